@@ -19,6 +19,8 @@ import (
 	"runtime/debug"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -50,12 +52,22 @@ type APITest struct {
 	mocksObservers           []Observe
 	recorderHook             RecorderHook
 	mocks                    []*Mock
+	cassette                 *Cassette
+	scenarios                *Scenarios
+	passthrough              *passthrough
+	upstreams                *upstreams
+	inOrder                  [][]*Mock
+	strictMocks              bool
+	denyNetwork              bool
+	awaitMocks               time.Duration
+	mockFiles                []string
 	t                        TestingT
 	httpClient               *http.Client
 	httpRequest              *http.Request
 	transport                *Transport
 	meta                     map[string]interface{}
 	started                  time.Time
+	responded                time.Time
 	finished                 time.Time
 	fileSystem               fs.FS
 }
@@ -170,21 +182,113 @@ func (a *APITest) HandlerFunc(handlerFunc http.HandlerFunc) *APITest {
 
 // Mocks is a builder method for setting the mocks
 func (a *APITest) Mocks(mocks ...*Mock) *APITest {
+	a.mocks = expandMocks(mocks)
+	return a
+}
+
+// InOrder verifies that the given mocks are invoked in the given order, i.e. each mock is invoked and all the calls to
+// a mock happen after the calls to the previous mock. The mocks must also be added to the test using Mocks
+func (a *APITest) InOrder(mocks ...*Mock) *APITest {
+	a.inOrder = append(a.inOrder, mocks)
+	return a
+}
+
+// StrictMocks fails the test if any mock is not invoked the expected number of times, including mocks that do not
+// define Times and mocks defined using AnyTimes that are never invoked
+func (a *APITest) StrictMocks() *APITest {
+	a.strictMocks = true
+	return a
+}
+
+// DenyNetwork fails any outbound http request that does not match a mock, and fails the test if such a request is
+// made, even if the test has no mocks. Clients that build their own transport are covered if they dial using
+// DialContext and send requests with the context of the inbound request. This has no effect if networking is enabled
+func (a *APITest) DenyNetwork() *APITest {
+	a.denyNetwork = true
+	return a
+}
+
+// AwaitMocks waits up to the timeout after the response is received for the mocks that define the number of times
+// they are invoked, using Times or a range such as AtLeast, to receive the expected calls. Use it when the handler
+// calls the mocks asynchronously, e.g. in a goroutine after responding 202 Accepted
+func (a *APITest) AwaitMocks(timeout time.Duration) *APITest {
+	a.awaitMocks = timeout
+	return a
+}
+
+// MocksFromFile loads mocks from YAML or JSON mock definition files, see MocksFromFile for the file format.
+// The files are read using the filesystem defined by UseFS and the loaded mocks are added after the mocks defined using Mocks
+func (a *APITest) MocksFromFile(paths ...string) *APITest {
+	a.mockFiles = append(a.mockFiles, paths...)
+	return a
+}
+
+// expectedCalls returns the number of calls expected by a mock defined using Times, which is the number of copies of
+// the mock made by expandMocks
+func expectedCalls(mock *Mock, mocks []*Mock) int {
+	expected := 0
+	for _, m := range mocks {
+		if m.calls == mock.calls {
+			expected++
+		}
+	}
+	return expected
+}
+
+func expandMocks(mocks []*Mock) []*Mock {
 	var m []*Mock
 	for i := range mocks {
-		if mocks[i].anyTimesSet {
+		if mocks[i].unlimited() {
 			m = append(m, mocks[i])
 			continue
 		}
 
-		times := mocks[i].response.mock.times
+		times := mocks[i].response.mock.times + mocks[i].response.failedCalls()
 		for j := 1; j <= times; j++ {
 			mockCpy := mocks[i].copy()
 			mockCpy.times = 1
 			m = append(m, mockCpy)
 		}
 	}
-	a.mocks = m
+	return m
+}
+
+// PassthroughUnmatched forwards outbound requests that do not match any mock to the real network if the request
+// host is in the given list of hosts, e.g. "localhost:8081" or "localhost". No requests are forwarded if no hosts are
+// given. This allows a test to mock some dependencies while calling others, such as a local stub server
+func (a *APITest) PassthroughUnmatched(hosts ...string) *APITest {
+	a.passthrough = newPassthrough(hosts)
+	return a
+}
+
+// PassthroughAllUnmatched forwards outbound requests to any host that do not match any mock to the real network
+func (a *APITest) PassthroughAllUnmatched() *APITest {
+	a.passthrough = &passthrough{all: true}
+	return a
+}
+
+// Upstream serves outbound requests to the given host, e.g. "users-service" or "localhost:8081", using the in-process
+// handler, so that several services can be tested together without network setup. Requests that match a mock are
+// served by the mock. The requests and responses of the upstream are added to the report
+func (a *APITest) Upstream(host string, handler http.Handler) *APITest {
+	if a.upstreams == nil {
+		a.upstreams = &upstreams{}
+	}
+	a.upstreams.add(host, handler)
+	return a
+}
+
+// Scenarios sets the scenario state store used by the mocks. By default each test starts with all scenarios in the
+// ScenarioStarted state. Provide a store to inspect the scenario states after the test or to share them between tests
+func (a *APITest) Scenarios(scenarios *Scenarios) *APITest {
+	a.scenarios = scenarios
+	return a
+}
+
+// Cassette records the outbound http interactions of the test to the cassette file, or replays them as mocks if the
+// cassette was already recorded. Mocks defined using Mocks take precedence over the interactions in the cassette
+func (a *APITest) Cassette(cassette *Cassette) *APITest {
+	a.cassette = cassette
 	return a
 }
 
@@ -715,19 +819,15 @@ func (r *Response) End() Result {
 		res = r.runTest()
 	}
 
-	var unmatchedMocks []UnmatchedMock
-	for _, m := range r.apiTest.mocks {
-		if !m.isUsed {
-			unmatchedMocks = append(unmatchedMocks, UnmatchedMock{
-				URL: *m.request.url,
-			})
-			break
-		}
+	var mockCalls []MockCall
+	if r.apiTest.transport != nil {
+		mockCalls = r.apiTest.transport.calls.list()
 	}
 
 	return Result{
 		Response:       res,
-		unmatchedMocks: unmatchedMocks,
+		unmatchedMocks: unmatchedMocks(r.apiTest.mocks),
+		mockCalls:      mockCalls,
 	}
 }
 
@@ -735,6 +835,12 @@ func (r *Response) End() Result {
 type Result struct {
 	Response       *http.Response
 	unmatchedMocks []UnmatchedMock
+	mockCalls      []MockCall
+}
+
+// MockCalls returns the requests matched by the mocks of the test and the responses served, in the order they were received
+func (r Result) MockCalls() []MockCall {
+	return r.mockCalls
 }
 
 // UnmatchedMocks returns any mocks that were not used, e.g. there was not a matching http Request for the mock
@@ -742,6 +848,13 @@ func (r Result) UnmatchedMocks() []UnmatchedMock {
 	return r.unmatchedMocks
 }
 
+// AssertAllMocksUsed fails the test if any mock was not invoked the expected number of times
+func (r Result) AssertAllMocksUsed(t TestingT) {
+	if len(r.unmatchedMocks) > 0 {
+		t.Errorf("%s", formatUnmatchedMocks(r.unmatchedMocks))
+	}
+}
+
 // JSON unmarshal the result response body to a valid struct
 func (r Result) JSON(t interface{}) {
 	data, err := ioutil.ReadAll(r.Response.Body)
@@ -755,8 +868,11 @@ func (r Result) JSON(t interface{}) {
 }
 
 type mockInteraction struct {
+	source    string
 	request   *http.Request
 	response  *http.Response
+	err       error
+	started   time.Time
 	timestamp time.Time
 }
 
@@ -768,22 +884,64 @@ func (r *mockInteraction) GetRequestHost() string {
 	return host
 }
 
+// newMockInteraction copies the request and response observed by the transport
+func newMockInteraction(res *http.Response, req *http.Request) *mockInteraction {
+	interaction := &mockInteraction{
+		source:    requestSource(req),
+		request:   copyHttpRequest(req),
+		response:  copyHttpResponse(res),
+		err:       mockMatchError(req),
+		started:   mockRequestStarted(req),
+		timestamp: time.Now().UTC(),
+	}
+	if interaction.started.IsZero() {
+		interaction.started = interaction.timestamp
+	}
+	return interaction
+}
+
+// addMockInteractions adds the requests sent to the mocks and the responses of the mocks to the recorder
+func addMockInteractions(recorder *Recorder, interactions []*mockInteraction) {
+	for _, interaction := range interactions {
+		request := HttpRequest{
+			Source:    interaction.source,
+			Target:    interaction.GetRequestHost(),
+			Value:     interaction.request,
+			Timestamp: interaction.started,
+		}
+		if interaction.err != nil {
+			request.Error = interaction.err.Error()
+		}
+		recorder.AddHttpRequest(request)
+		if interaction.response != nil {
+			interaction.response.Request = interaction.request
+			recorder.AddHttpResponse(HttpResponse{
+				Source:    interaction.GetRequestHost(),
+				Target:    interaction.source,
+				Value:     interaction.response,
+				Timestamp: interaction.timestamp,
+			})
+		}
+	}
+}
+
 func (a *APITest) report() *http.Response {
 	var capturedInboundReq *http.Request
 	var capturedFinalRes *http.Response
 	var capturedMockInteractions []*mockInteraction
+	var capturedMockInteractionsMu sync.Mutex
 
 	a.observers = append(a.observers, func(finalRes *http.Response, inboundReq *http.Request, a *APITest) {
 		capturedFinalRes = copyHttpResponse(finalRes)
 		capturedInboundReq = copyHttpRequest(inboundReq)
+		capturedFinalRes.Request = capturedInboundReq
 	})
 
 	a.mocksObservers = append(a.mocksObservers, func(mockRes *http.Response, mockReq *http.Request, a *APITest) {
-		capturedMockInteractions = append(capturedMockInteractions, &mockInteraction{
-			request:   copyHttpRequest(mockReq),
-			response:  copyHttpResponse(mockRes),
-			timestamp: time.Now().UTC(),
-		})
+		interaction := newMockInteraction(mockRes, mockReq)
+		capturedMockInteractionsMu.Lock()
+		capturedMockInteractions = append(capturedMockInteractions, interaction)
+		capturedMockInteractionsMu.Unlock()
 	})
 
 	if a.recorder == nil {
@@ -809,31 +967,18 @@ func (a *APITest) report() *http.Response {
 			Timestamp: a.started,
 		})
 
-	for _, interaction := range capturedMockInteractions {
-		a.recorder.AddHttpRequest(HttpRequest{
-			Source:    SystemUnderTestDefaultName,
-			Target:    interaction.GetRequestHost(),
-			Value:     interaction.request,
-			Timestamp: interaction.timestamp,
-		})
-		if interaction.response != nil {
-			a.recorder.AddHttpResponse(HttpResponse{
-				Source:    interaction.GetRequestHost(),
-				Target:    SystemUnderTestDefaultName,
-				Value:     interaction.response,
-				Timestamp: interaction.timestamp,
-			})
-		}
-	}
+	capturedMockInteractionsMu.Lock()
+	addMockInteractions(a.recorder, capturedMockInteractions)
+	capturedMockInteractionsMu.Unlock()
 
 	a.recorder.AddHttpResponse(HttpResponse{
 		Source:    SystemUnderTestDefaultName,
 		Target:    ConsumerDefaultName,
 		Value:     capturedFinalRes,
-		Timestamp: a.finished,
+		Timestamp: a.responded,
 	})
 
-	sort.Slice(a.recorder.Events, func(i, j int) bool {
+	sort.SliceStable(a.recorder.Events, func(i, j int) bool {
 		return a.recorder.Events[i].GetTime().Before(a.recorder.Events[j].GetTime())
 	})
 
@@ -878,19 +1023,68 @@ func createHash(meta map[string]interface{}) string {
 
 func (r *Response) runTest() *http.Response {
 	a := r.apiTest
-	if len(a.mocks) > 0 {
+	for _, path := range a.mockFiles {
+		fileMocks, err := MocksFromFS(a.fileSystem, path)
+		if err != nil {
+			a.t.Fatal(err)
+		}
+		a.mocks = append(a.mocks, expandMocks(fileMocks)...)
+	}
+
+	for _, mock := range a.mocks {
+		mock.calls.reset()
+		mock.response.failFirst.reset()
+	}
+
+	denyNetwork := (a.denyNetwork || DenyNetworkByDefault) && !a.networkingEnabled
+
+	if len(a.mocks) > 0 || a.cassette != nil || a.upstreams != nil || denyNetwork {
+		mocks, recordingCassette := a.mocks, (*Cassette)(nil)
+		if a.cassette != nil {
+			if a.cassette.isRecording() {
+				recordingCassette = a.cassette
+			} else {
+				cassetteMocks, err := a.cassette.Mocks()
+				if err != nil {
+					a.t.Fatal(err)
+				}
+				mocks = append(append([]*Mock{}, a.mocks...), cassetteMocks...)
+			}
+		}
+
 		a.transport = newTransport(
-			a.mocks,
+			mocks,
 			a.httpClient,
 			a.debugEnabled,
 			a.mockResponseDelayEnabled,
 			a.mocksObservers,
 			r.apiTest,
 		)
+		a.transport.cassette = recordingCassette
+		a.transport.scenarios = a.scenarios
+		a.transport.passthrough = a.passthrough
+		a.transport.upstreams = a.upstreams
+		if denyNetwork {
+			a.transport.denied = &deniedRequests{}
+		}
+		if a.transport.scenarios == nil {
+			a.transport.scenarios = NewScenarios()
+		}
 		defer a.transport.Reset()
 		a.transport.Hijack()
 	}
 	res, req := a.doRequest()
+	a.responded = time.Now()
+
+	if a.awaitMocks > 0 {
+		a.waitForMocks(a.awaitMocks)
+	}
+
+	if a.transport != nil && a.transport.cassette != nil {
+		if err := a.transport.cassette.save(); err != nil {
+			a.t.Fatal(err)
+		}
+	}
 
 	defer func() {
 		if len(a.observers) > 0 {
@@ -913,20 +1107,143 @@ func (r *Response) runTest() *http.Response {
 	return copyHttpResponse(res)
 }
 
+// verify returns the verifier for the named assertion. When a recorder is defined the outcome of the assertion
+// is also added to the recorder so that it can be rendered in the report
+func (a *APITest) verify(name string) Verifier {
+	if a.recorder == nil {
+		return a.verifier
+	}
+	return recordingVerifier{name: name, verifier: a.verifier, recorder: a.recorder}
+}
+
+func (a *APITest) recordPassedAssertion(name string) {
+	if a.recorder != nil {
+		a.recorder.AddAssertion(Assertion{Name: name, Passed: true})
+	}
+}
+
+// mockPollInterval is the interval at which AwaitMocks checks the calls received by the mocks
+const mockPollInterval = 5 * time.Millisecond
+
+// waitForMocks blocks until the mocks have received the expected calls or the timeout ends
+func (a *APITest) waitForMocks(timeout time.Duration) {
+	deadline := time.Now().Add(timeout)
+	for !a.mocksSatisfied() && time.Now().Before(deadline) {
+		time.Sleep(mockPollInterval)
+	}
+}
+
+// mocksSatisfied returns true if every mock that defines the number of times it is invoked received the calls and
+// the observers have received all the calls
+func (a *APITest) mocksSatisfied() bool {
+	if a.transport != nil && atomic.LoadInt64(&a.transport.inFlight) > 0 {
+		return false
+	}
+	for _, mock := range a.mocks {
+		if mock.callRange != nil {
+			if mock.calls.count() < mock.callRange.min {
+				return false
+			}
+			continue
+		}
+		mock.m.Lock()
+		satisfied := mock.isUsed || !mock.timesSet || mock.anyTimesSet
+		mock.m.Unlock()
+		if !satisfied {
+			return false
+		}
+	}
+	return true
+}
+
 func (a *APITest) assertMocks() {
+	reported := map[*mockCalls]bool{}
 	for _, mock := range a.mocks {
-		if mock.anyTimesSet == false && mock.isUsed == false && mock.timesSet {
-			a.verifier.Fail(a.t, "mock was not invoked expected times", failureMessageArgs{Name: a.name})
+		name := fmt.Sprintf("Mock %s %s", mock.request.method, mock.request.url)
+		if mock.callRange != nil {
+			if calls := mock.calls.count(); !mock.callRange.contains(calls) {
+				message := fmt.Sprintf("mock expected %s calls but received %d\n\n%s", mock.callRange, calls, formatMockCalls(a.mockCalls()))
+				a.verify(name).Fail(a.t, message, failureMessageArgs{Name: a.name})
+			} else {
+				a.recordPassedAssertion(name)
+			}
+		} else if mock.anyTimesSet == false && mock.isUsed == false && mock.timesSet {
+			if !reported[mock.calls] {
+				reported[mock.calls] = true
+				message := fmt.Sprintf("mock expected %d calls but received %d\n\n%s", expectedCalls(mock, a.mocks), mock.calls.count(), formatMockCalls(a.mockCalls()))
+				a.verify(name).Fail(a.t, message, failureMessageArgs{Name: a.name})
+			}
+		} else if mock.timesSet {
+			a.recordPassedAssertion(name)
+		}
+	}
+
+	if a.strictMocks {
+		if unmatched := unmatchedMocks(a.mocks); len(unmatched) > 0 {
+			a.verify("All mocks used").Fail(a.t, formatUnmatchedMocks(unmatched), failureMessageArgs{Name: a.name})
+		} else {
+			a.recordPassedAssertion("All mocks used")
+		}
+	}
+
+	if a.transport != nil && a.transport.denied != nil {
+		if denied := a.transport.denied.String(); denied != "" {
+			a.verify("Network access").Fail(a.t, denied, failureMessageArgs{Name: a.name})
+		}
+	}
+
+	for _, mocks := range a.inOrder {
+		if err := verifyMocksInOrder(mocks); err != nil {
+			message := fmt.Sprintf("%s\n\n%s", err, formatMockCalls(a.mockCalls()))
+			a.verify("Mocks in order").Fail(a.t, message, failureMessageArgs{Name: a.name})
+		} else {
+			a.recordPassedAssertion("Mocks in order")
 		}
 	}
 }
 
+// mockCalls returns the calls of all the mocks of the test
+func (a *APITest) mockCalls() []MockCall {
+	if a.transport == nil {
+		return nil
+	}
+	return a.transport.calls.list()
+}
+
+// verifyMocksInOrder checks that each mock was invoked and all the calls to a mock happened after the calls to the previous mock
+func verifyMocksInOrder(mocks []*Mock) error {
+	var previous *Mock
+	var previousLast int64
+	for _, mock := range mocks {
+		calls := mock.calls.list()
+		if len(calls) == 0 {
+			return fmt.Errorf("expected mock %s %s to be invoked in order but it was not invoked", mock.request.method, mock.request.url)
+		}
+		first, last := calls[0].sequence, calls[0].sequence
+		for _, call := range calls {
+			if call.sequence < first {
+				first = call.sequence
+			}
+			if call.sequence > last {
+				last = call.sequence
+			}
+		}
+		if previous != nil && first < previousLast {
+			return fmt.Errorf("expected mock %s %s to be invoked after mock %s %s", mock.request.method, mock.request.url, previous.request.method, previous.request.url)
+		}
+		previous, previousLast = mock, last
+	}
+	return nil
+}
+
 func (a *APITest) assertFunc(res *http.Response, req *http.Request) {
 	if len(a.response.assert) > 0 {
-		for _, assertFn := range a.response.assert {
+		for i, assertFn := range a.response.assert {
 			err := assertFn(copyHttpResponse(res), copyHttpRequest(req))
 			if err != nil {
-				a.verifier.NoError(a.t, err, failureMessageArgs{Name: a.name})
+				a.verify(fmt.Sprintf("Assert #%d", i+1)).NoError(a.t, err, failureMessageArgs{Name: a.name})
+			} else {
+				a.recordPassedAssertion(fmt.Sprintf("Assert #%d", i+1))
 			}
 		}
 	}
@@ -949,7 +1266,11 @@ func (a *APITest) doRequest() (*http.Response, *http.Request) {
 	var res *http.Response
 	var err error
 	if !a.networkingEnabled {
-		a.serveHttp(resRecorder, copyHttpRequest(req))
+		inboundReq := copyHttpRequest(req)
+		if a.transport != nil && a.transport.denied != nil {
+			inboundReq = inboundReq.WithContext(context.WithValue(inboundReq.Context(), denyNetworkKey{}, true))
+		}
+		a.serveHttp(resRecorder, inboundReq)
 		res = resRecorder.Result()
 	} else {
 		res, err = a.networkingHTTPClient.Do(copyHttpRequest(req))
@@ -1076,7 +1397,7 @@ func buildQueryCollection(params map[string][]string) []pair {
 
 func (a *APITest) assertResponse(res *http.Response) {
 	if a.response.status != 0 {
-		a.verifier.Equal(a.t, a.response.status, res.StatusCode, fmt.Sprintf("Status code %d not equal to %d", res.StatusCode, a.response.status), failureMessageArgs{Name: a.name})
+		a.verify("Status code").Equal(a.t, a.response.status, res.StatusCode, fmt.Sprintf("Status code %d not equal to %d", res.StatusCode, a.response.status), failureMessageArgs{Name: a.name})
 	}
 
 	if a.response.body != "" {
@@ -1086,9 +1407,9 @@ func (a *APITest) assertResponse(res *http.Response) {
 			res.Body = ioutil.NopCloser(bytes.NewBuffer(resBodyBytes))
 		}
 		if json.Valid([]byte(a.response.body)) {
-			a.verifier.JSONEq(a.t, a.response.body, string(resBodyBytes), failureMessageArgs{Name: a.name})
+			a.verify("Body").JSONEq(a.t, a.response.body, string(resBodyBytes), failureMessageArgs{Name: a.name})
 		} else {
-			a.verifier.Equal(a.t, a.response.body, string(resBodyBytes), failureMessageArgs{Name: a.name})
+			a.verify("Body").Equal(a.t, a.response.body, string(resBodyBytes), failureMessageArgs{Name: a.name})
 		}
 	}
 }
@@ -1105,8 +1426,8 @@ func (a *APITest) assertCookies(response *http.Response) {
 					mismatchedFields = append(mismatchedFields, errors...)
 				}
 			}
-			a.verifier.Equal(a.t, true, foundCookie, "ExpectedCookie not found - "+*expectedCookie.name, failureMessageArgs{Name: a.name})
-			a.verifier.Equal(a.t, 0, len(mismatchedFields), strings.Join(mismatchedFields, ","), failureMessageArgs{Name: a.name})
+			a.verify("Cookie "+*expectedCookie.name).Equal(a.t, true, foundCookie, "ExpectedCookie not found - "+*expectedCookie.name, failureMessageArgs{Name: a.name})
+			a.verify("Cookie "+*expectedCookie.name+" fields").Equal(a.t, 0, len(mismatchedFields), strings.Join(mismatchedFields, ","), failureMessageArgs{Name: a.name})
 		}
 	}
 
@@ -1118,7 +1439,7 @@ func (a *APITest) assertCookies(response *http.Response) {
 					foundCookie = true
 				}
 			}
-			a.verifier.Equal(a.t, true, foundCookie, "ExpectedCookie not found - "+cookieName, failureMessageArgs{Name: a.name})
+			a.verify("Cookie "+cookieName+" present").Equal(a.t, true, foundCookie, "ExpectedCookie not found - "+cookieName, failureMessageArgs{Name: a.name})
 		}
 	}
 
@@ -1130,7 +1451,7 @@ func (a *APITest) assertCookies(response *http.Response) {
 					foundCookie = true
 				}
 			}
-			a.verifier.Equal(a.t, false, foundCookie, "ExpectedCookie found - "+cookieName, failureMessageArgs{Name: a.name})
+			a.verify("Cookie "+cookieName+" not present").Equal(a.t, false, foundCookie, "ExpectedCookie found - "+cookieName, failureMessageArgs{Name: a.name})
 		}
 	}
 }
@@ -1138,7 +1459,7 @@ func (a *APITest) assertCookies(response *http.Response) {
 func (a *APITest) assertHeaders(res *http.Response) {
 	for expectedHeader, expectedValues := range a.response.headers {
 		resHeaderValues, foundHeader := res.Header[expectedHeader]
-		a.verifier.Equal(a.t, true, foundHeader, fmt.Sprintf("expected header '%s' not present in response", expectedHeader), failureMessageArgs{Name: a.name})
+		a.verify("Header "+expectedHeader).Equal(a.t, true, foundHeader, fmt.Sprintf("expected header '%s' not present in response", expectedHeader), failureMessageArgs{Name: a.name})
 
 		if foundHeader {
 			for _, expectedValue := range expectedValues {
@@ -1149,7 +1470,7 @@ func (a *APITest) assertHeaders(res *http.Response) {
 						break
 					}
 				}
-				a.verifier.Equal(a.t, true, foundValue, fmt.Sprintf("mismatched values for header '%s'. Expected %s but received %s", expectedHeader, expectedValue, strings.Join(resHeaderValues, ",")), failureMessageArgs{Name: a.name})
+				a.verify("Header "+expectedHeader+": "+expectedValue).Equal(a.t, true, foundValue, fmt.Sprintf("mismatched values for header '%s'. Expected %s but received %s", expectedHeader, expectedValue, strings.Join(resHeaderValues, ",")), failureMessageArgs{Name: a.name})
 			}
 		}
 	}
@@ -1157,7 +1478,9 @@ func (a *APITest) assertHeaders(res *http.Response) {
 	if len(a.response.headersPresent) > 0 {
 		for _, expectedName := range a.response.headersPresent {
 			if res.Header.Get(expectedName) == "" {
-				a.verifier.Fail(a.t, fmt.Sprintf("expected header '%s' not present in response", expectedName), failureMessageArgs{Name: a.name})
+				a.verify("Header "+expectedName+" present").Fail(a.t, fmt.Sprintf("expected header '%s' not present in response", expectedName), failureMessageArgs{Name: a.name})
+			} else {
+				a.recordPassedAssertion("Header " + expectedName + " present")
 			}
 		}
 	}
@@ -1165,7 +1488,9 @@ func (a *APITest) assertHeaders(res *http.Response) {
 	if len(a.response.headersNotPresent) > 0 {
 		for _, name := range a.response.headersNotPresent {
 			if res.Header.Get(name) != "" {
-				a.verifier.Fail(a.t, fmt.Sprintf("did not expect header '%s' in response", name), failureMessageArgs{Name: a.name})
+				a.verify("Header "+name+" not present").Fail(a.t, fmt.Sprintf("did not expect header '%s' in response", name), failureMessageArgs{Name: a.name})
+			} else {
+				a.recordPassedAssertion("Header " + name + " not present")
 			}
 		}
 	}
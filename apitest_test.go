@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
 	"io/ioutil"
 	"net/http"
@@ -933,6 +934,95 @@ func TestApiTest_Report(t *testing.T) {
 	assert.Equal(t, true, r.Meta["duration"] != nil)
 }
 
+func TestApiTest_Upstream(t *testing.T) {
+	getPrices := apitest.NewMock().
+		Get("http://prices/prices/1").
+		RespondWith().
+		Body(`9.99`).
+		End()
+	products := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req, _ := http.NewRequestWithContext(r.Context(), http.MethodGet, "http://prices/prices/"+r.URL.Query().Get("id"), nil)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		price, _ := ioutil.ReadAll(res.Body)
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"host": "%s", "price": %s}`, r.Host, price)))
+	})
+	reporter := &RecorderCaptor{}
+
+	apitest.New().
+		Report(reporter).
+		Mocks(getPrices).
+		Upstream("products:8080", products).
+		Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			res, err := http.Get("http://products:8080/products?id=1")
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, _ = io.Copy(w, res.Body)
+		})).
+		Get("/").
+		Expect(t).
+		Status(http.StatusOK).
+		Body(`{"host": "products:8080", "price": 9.99}`).
+		End()
+
+	var hops []string
+	for _, event := range reporter.capturedRecorder.Events {
+		switch e := event.(type) {
+		case apitest.HttpRequest:
+			hops = append(hops, e.Source+" -> "+e.Target)
+		case apitest.HttpResponse:
+			hops = append(hops, e.Source+" -> "+e.Target)
+		}
+	}
+	assert.Equal(t, []string{
+		"cli -> sut",
+		"sut -> products:8080",
+		"products:8080 -> prices",
+		"prices -> products:8080",
+		"products:8080 -> sut",
+		"sut -> cli",
+	}, hops)
+}
+
+func TestApiTest_Report_RecordsAssertions(t *testing.T) {
+	reporter := &RecorderCaptor{}
+
+	apitest.New("some test").
+		Report(reporter).
+		Verifier(mocks.NewVerifier()).
+		Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			_, _ = w.Write([]byte(`{"a": 1}`))
+		})).
+		Get("/hello").
+		Expect(t).
+		Status(http.StatusOK).
+		Header("Content-Type", "application/json").
+		Body(`{"a": 2}`).
+		Assert(apitest.IsSuccess).
+		End()
+
+	r := reporter.capturedRecorder
+	assert.Equal(t, false, r.Passed())
+	assert.Equal(t, 5, len(r.Assertions))
+	assert.Equal(t, apitest.Assertion{Name: "Status code", Passed: true, Expected: "200", Actual: "200"}, r.Assertions[0])
+	assert.Equal(t, "Body", r.Assertions[1].Name)
+	assert.Equal(t, false, r.Assertions[1].Passed)
+	assert.Equal(t, `{"a": 2}`, r.Assertions[1].Expected)
+	assert.Equal(t, `{"a": 1}`, r.Assertions[1].Actual)
+	assert.Equal(t, true, strings.Contains(r.Assertions[1].Diff, `- (string) (len=1) "a": (float64) 2`))
+	assert.Equal(t, "Header Content-Type", r.Assertions[2].Name)
+	assert.Equal(t, true, r.Assertions[2].Passed)
+	assert.Equal(t, "Header Content-Type: application/json", r.Assertions[3].Name)
+	assert.Equal(t, apitest.Assertion{Name: "Assert #1", Passed: true}, r.Assertions[4])
+}
+
 func TestApiTest_Recorder(t *testing.T) {
 	getUser := apitest.NewMock().
 		Get("http://localhost:8080").
@@ -1448,7 +1538,7 @@ func TestApiTest_ErrorIfMockInvocationsDoNotMatchTimes(t *testing.T) {
 
 	verifier := mocks.NewVerifier()
 	verifier.FailFn = func(t apitest.TestingT, failureMessage string, msgAndArgs ...interface{}) bool {
-		assert.Equal(t, "mock was not invoked expected times", failureMessage)
+		assert.Equal(t, "mock expected 2 calls but received 1\n\nmock calls received:\n1. GET http://localhost:8080 -> 200", failureMessage)
 		return true
 	}
 
@@ -1469,6 +1559,464 @@ func TestApiTest_ErrorIfMockInvocationsDoNotMatchTimes(t *testing.T) {
 	assert.Equal(t, "http://localhost:8080", unmatchedMocks[0].URL.String())
 }
 
+func TestApiTest_UnmatchedMocksReportsEveryUnusedMock(t *testing.T) {
+	getUser := apitest.NewMock().
+		Get("http://localhost:8080").
+		RespondWith().
+		Status(http.StatusOK).
+		Times(2).
+		End()
+	getPosts := apitest.NewMock().
+		Get("http://localhost:8080/posts").
+		Query("page", "1").
+		Header("Authorization", "Bearer abc").
+		RespondWith().
+		Status(http.StatusOK).
+		End()
+	createPost := apitest.NewMock().
+		Post("http://localhost:8080/posts").
+		Body(`{"title": "hello"}`).
+		RespondWith().
+		Status(http.StatusCreated).
+		AnyTimes().
+		End()
+
+	res := apitest.New().
+		Mocks(getUser, getPosts, createPost).
+		Verifier(mocks.NewVerifier()).
+		Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = getUserData()
+			w.WriteHeader(http.StatusOK)
+		})).
+		Get("/").
+		Expect(t).
+		Status(http.StatusOK).
+		End()
+
+	unmatchedMocks := res.UnmatchedMocks()
+	assert.Equal(t, 3, len(unmatchedMocks))
+	assert.Equal(t, http.MethodGet, unmatchedMocks[0].Method)
+	assert.Equal(t, "http://localhost:8080", unmatchedMocks[0].URL.String())
+	assert.Equal(t, 2, unmatchedMocks[0].Times)
+	assert.Equal(t, 1, unmatchedMocks[0].Calls)
+	assert.Equal(t, "GET http://localhost:8080 expected 2 calls but received 1", unmatchedMocks[0].String())
+	assert.Equal(t, map[string][]string{"page": {"1"}}, unmatchedMocks[1].Query)
+	assert.Equal(t, map[string][]string{"Authorization": {"Bearer abc"}}, unmatchedMocks[1].Headers)
+	assert.Equal(t, 1, unmatchedMocks[1].Times)
+	assert.Equal(t, 0, unmatchedMocks[1].Calls)
+	assert.Equal(t, "GET http://localhost:8080/posts was not invoked", unmatchedMocks[1].String())
+	assert.Equal(t, `{"title": "hello"}`, unmatchedMocks[2].Body)
+	assert.Equal(t, 0, unmatchedMocks[2].Times)
+}
+
+func TestApiTest_StrictMocksFailsIfMockNotUsed(t *testing.T) {
+	getUser := apitest.NewMock().
+		Get("http://localhost:8080").
+		RespondWith().
+		Status(http.StatusOK).
+		End()
+	getPosts := apitest.NewMock().
+		Get("http://localhost:8080/posts").
+		RespondWith().
+		Status(http.StatusOK).
+		End()
+
+	var failureMessages []string
+	verifier := mocks.NewVerifier()
+	verifier.FailFn = func(t apitest.TestingT, failureMessage string, msgAndArgs ...interface{}) bool {
+		failureMessages = append(failureMessages, failureMessage)
+		return true
+	}
+
+	apitest.New().
+		Mocks(getUser, getPosts).
+		StrictMocks().
+		Verifier(verifier).
+		Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = getUserData()
+			w.WriteHeader(http.StatusOK)
+		})).
+		Get("/").
+		Expect(t).
+		Status(http.StatusOK).
+		End()
+
+	assert.Equal(t, []string{"mocks were not used:\n1. GET http://localhost:8080/posts was not invoked"}, failureMessages)
+}
+
+func TestApiTest_AssertAllMocksUsed(t *testing.T) {
+	getUser := apitest.NewMock().
+		Get("http://localhost:8080").
+		RespondWith().
+		Status(http.StatusOK).
+		End()
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	res := apitest.New().
+		Mocks(getUser).
+		Handler(handler).
+		Get("/").
+		Expect(t).
+		Status(http.StatusOK).
+		End()
+	recorder := &testingTRecorder{}
+	res.AssertAllMocksUsed(recorder)
+
+	assert.Equal(t, []string{"mocks were not used:\n1. GET http://localhost:8080 was not invoked"}, recorder.errors)
+}
+
+func TestApiTest_DenyNetworkFailsUnmockedRequests(t *testing.T) {
+	var failureMessages []string
+	verifier := mocks.NewVerifier()
+	verifier.FailFn = func(t apitest.TestingT, failureMessage string, msgAndArgs ...interface{}) bool {
+		failureMessages = append(failureMessages, failureMessage)
+		return true
+	}
+
+	apitest.New().
+		DenyNetwork().
+		Verifier(verifier).
+		Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := http.Get("http://localhost:8080/users?id=1")
+			if err == nil {
+				t.Fatal("expected the request to fail")
+			}
+			w.WriteHeader(http.StatusOK)
+		})).
+		Get("/").
+		Expect(t).
+		Status(http.StatusOK).
+		End()
+
+	assert.Equal(t, []string{"network access denied, outbound requests did not match any mocks:\n1. GET http://localhost:8080/users?id=1"}, failureMessages)
+}
+
+func TestApiTest_DenyNetworkAllowsMockedRequests(t *testing.T) {
+	getUser := apitest.NewMock().
+		Get("http://localhost:8080").
+		RespondWith().
+		Status(http.StatusOK).
+		End()
+
+	apitest.New().
+		DenyNetwork().
+		Mocks(getUser).
+		Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = getUserData()
+			w.WriteHeader(http.StatusOK)
+		})).
+		Get("/").
+		Expect(t).
+		Status(http.StatusOK).
+		End()
+}
+
+func TestApiTest_DenyNetworkFailsDialContext(t *testing.T) {
+	local := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer local.Close()
+	client := &http.Client{Transport: &http.Transport{DialContext: apitest.DialContext}}
+
+	apitest.New().
+		DenyNetwork().
+		Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req, _ := http.NewRequestWithContext(r.Context(), http.MethodGet, "http://192.0.2.1:8080", nil)
+			_, err := client.Do(req)
+			if err == nil || !strings.Contains(err.Error(), "network access to 192.0.2.1:8080 denied by apitest") {
+				t.Fatalf("unexpected error %v", err)
+			}
+			req, _ = http.NewRequestWithContext(r.Context(), http.MethodGet, local.URL, nil)
+			if _, err := client.Do(req); err != nil {
+				t.Fatalf("unexpected error calling loopback address %v", err)
+			}
+			w.WriteHeader(http.StatusOK)
+		})).
+		Get("/").
+		Expect(t).
+		Status(http.StatusOK).
+		End()
+}
+
+func TestApiTest_RetriesRateLimitedMock(t *testing.T) {
+	getUser := apitest.NewMock().
+		Get("http://localhost:8080").
+		RespondWith().
+		RateLimitFirst(2, 0).
+		Status(http.StatusOK).
+		End()
+
+	apitest.New().
+		Mocks(getUser).
+		Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for attempt := 0; attempt < 5; attempt++ {
+				res, err := http.Get("http://localhost:8080")
+				if err == nil && res.StatusCode == http.StatusOK {
+					w.WriteHeader(http.StatusOK)
+					return
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			w.WriteHeader(http.StatusBadGateway)
+		})).
+		Get("/").
+		Expect(t).
+		Status(http.StatusOK).
+		End()
+
+	getUser.AssertRetries(t, 2, 10*time.Millisecond)
+	calls := getUser.Calls()
+	assert.Equal(t, http.StatusTooManyRequests, calls[0].Response.StatusCode)
+	assert.Equal(t, http.StatusTooManyRequests, calls[1].Response.StatusCode)
+	assert.Equal(t, http.StatusOK, calls[2].Response.StatusCode)
+}
+
+func TestApiTest_MockSetSharedBetweenTests(t *testing.T) {
+	downstream := apitest.NewMockSet().
+		Add("getUser", apitest.NewMock().
+			Get("http://localhost:8080").
+			RespondWith().
+			Status(http.StatusOK).
+			AnyTimes().
+			End())
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		res, err := http.Get("http://localhost:8080")
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(res.StatusCode)
+	})
+
+	for _, status := range []int{http.StatusOK, http.StatusServiceUnavailable} {
+		getUser := downstream.Get("getUser").WithStatus(status)
+
+		apitest.New().
+			Mocks(getUser).
+			Handler(handler).
+			Get("/").
+			Expect(t).
+			Status(status).
+			End()
+
+		assert.Equal(t, 1, len(getUser.Calls()))
+	}
+	assert.Equal(t, 0, len(downstream.Get("getUser").Calls()))
+}
+
+func TestApiTest_AwaitMocks(t *testing.T) {
+	getUser := apitest.NewMock().
+		Get("http://localhost:8080").
+		RespondWith().
+		Status(http.StatusOK).
+		Times(1).
+		End()
+	reporter := &RecorderCaptor{}
+	done := make(chan struct{})
+
+	apitest.New().
+		Report(reporter).
+		Mocks(getUser).
+		AwaitMocks(time.Second).
+		Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			go func() {
+				defer close(done)
+				time.Sleep(20 * time.Millisecond)
+				_ = getUserData()
+			}()
+			w.WriteHeader(http.StatusAccepted)
+		})).
+		Get("/").
+		Expect(t).
+		Status(http.StatusAccepted).
+		End()
+	<-done
+
+	assert.Equal(t, 1, len(getUser.Calls()))
+	var hops []string
+	for _, event := range reporter.capturedRecorder.Events {
+		switch e := event.(type) {
+		case apitest.HttpRequest:
+			hops = append(hops, e.Source+" -> "+e.Target)
+		case apitest.HttpResponse:
+			hops = append(hops, e.Source+" -> "+e.Target)
+		}
+	}
+	assert.Equal(t, []string{"cli -> sut", "sut -> cli", "sut -> localhost:8080", "localhost:8080 -> sut"}, hops)
+}
+
+func TestApiTest_AwaitMocksTimeout(t *testing.T) {
+	getUser := apitest.NewMock().
+		Get("http://localhost:8080").
+		RespondWith().
+		Status(http.StatusOK).
+		Times(1).
+		End()
+	var failureMessages []string
+	verifier := mocks.NewVerifier()
+	verifier.FailFn = func(t apitest.TestingT, failureMessage string, msgAndArgs ...interface{}) bool {
+		failureMessages = append(failureMessages, failureMessage)
+		return true
+	}
+	started := time.Now()
+
+	apitest.New().
+		Mocks(getUser).
+		AwaitMocks(30 * time.Millisecond).
+		Verifier(verifier).
+		Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+		})).
+		Get("/").
+		Expect(t).
+		Status(http.StatusAccepted).
+		End()
+
+	assert.True(t, time.Since(started) >= 30*time.Millisecond)
+	assert.Equal(t, []string{"mock expected 1 calls but received 0\n\nno mock calls received"}, failureMessages)
+}
+
+type testingTRecorder struct {
+	errors []string
+}
+
+func (r *testingTRecorder) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *testingTRecorder) Fatal(args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprint(args...))
+}
+
+func (r *testingTRecorder) Fatalf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func TestApiTest_ErrorIfMockInvocationsNotInRange(t *testing.T) {
+	tests := map[string]struct {
+		respond  func(*apitest.MockResponse) *apitest.MockResponse
+		calls    int
+		expected string
+	}{
+		"at least": {func(r *apitest.MockResponse) *apitest.MockResponse { return r.AtLeast(3) }, 2,
+			"mock expected at least 3 calls but received 2\n\nmock calls received:\n1. GET http://localhost:8080 -> 503\n2. GET http://localhost:8080 -> 503"},
+		"at most": {func(r *apitest.MockResponse) *apitest.MockResponse { return r.AtMost(1) }, 2,
+			"mock expected at most 1 calls but received 2\n\nmock calls received:\n1. GET http://localhost:8080 -> 503\n2. GET http://localhost:8080 -> 503"},
+		"between": {func(r *apitest.MockResponse) *apitest.MockResponse { return r.Between(2, 3) }, 1,
+			"mock expected between 2 and 3 calls but received 1\n\nmock calls received:\n1. GET http://localhost:8080 -> 503"},
+		"never": {func(r *apitest.MockResponse) *apitest.MockResponse { return r.Never() }, 1,
+			"mock expected 0 calls but received 1\n\nmock calls received:\n1. GET http://localhost:8080 -> 503"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			getUser := test.respond(apitest.NewMock().
+				Get("http://localhost:8080").
+				RespondWith().
+				Status(http.StatusServiceUnavailable)).
+				End()
+			var failureMessages []string
+			verifier := mocks.NewVerifier()
+			verifier.FailFn = func(t apitest.TestingT, failureMessage string, msgAndArgs ...interface{}) bool {
+				failureMessages = append(failureMessages, failureMessage)
+				return true
+			}
+
+			apitest.New().
+				Mocks(getUser).
+				Verifier(verifier).
+				Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					for i := 0; i < test.calls; i++ {
+						_ = getUserData()
+					}
+					w.WriteHeader(http.StatusOK)
+				})).
+				Get("/").
+				Expect(t).
+				Status(http.StatusOK).
+				End()
+
+			assert.Equal(t, []string{test.expected}, failureMessages)
+		})
+	}
+}
+
+func TestApiTest_MockInvocationsInRange(t *testing.T) {
+	getUser := apitest.NewMock().
+		Get("http://localhost:8080").
+		RespondWith().
+		Status(http.StatusOK).
+		Between(1, 3).
+		End()
+
+	apitest.New().
+		Mocks(getUser).
+		Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = getUserData()
+			_ = getUserData()
+			w.WriteHeader(http.StatusOK)
+		})).
+		Get("/").
+		Expect(t).
+		Status(http.StatusOK).
+		End()
+
+	assert.Equal(t, 2, len(getUser.Calls()))
+}
+
+func TestApiTest_InOrder(t *testing.T) {
+	primary := apitest.NewMock().
+		Get("http://primary.com/user").
+		RespondWith().
+		Status(http.StatusServiceUnavailable).
+		Times(2).
+		End()
+	fallback := apitest.NewMock().
+		Get("http://fallback.com/user").
+		RespondWith().
+		Status(http.StatusOK).
+		End()
+
+	tests := map[string]struct {
+		urls     []string
+		expected []string
+	}{
+		"in order": {[]string{"http://primary.com/user", "http://primary.com/user", "http://fallback.com/user"}, nil},
+		"out of order": {[]string{"http://primary.com/user", "http://fallback.com/user", "http://primary.com/user"}, []string{
+			"expected mock GET http://fallback.com/user to be invoked after mock GET http://primary.com/user\n\n" +
+				"mock calls received:\n1. GET http://primary.com/user -> 503\n2. GET http://fallback.com/user -> 200\n3. GET http://primary.com/user -> 503",
+		}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var failureMessages []string
+			verifier := mocks.NewVerifier()
+			verifier.FailFn = func(t apitest.TestingT, failureMessage string, msgAndArgs ...interface{}) bool {
+				failureMessages = append(failureMessages, failureMessage)
+				return true
+			}
+
+			apitest.New().
+				Mocks(primary, fallback).
+				InOrder(primary, fallback).
+				Verifier(verifier).
+				Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					for _, u := range test.urls {
+						if _, err := http.Get(u); err != nil {
+							panic(err)
+						}
+					}
+					w.WriteHeader(http.StatusOK)
+				})).
+				Get("/").
+				Expect(t).
+				Status(http.StatusOK).
+				End()
+
+			assert.Equal(t, test.expected, failureMessages)
+		})
+	}
+}
+
 func TestApiTest_MatchesTimes(t *testing.T) {
 	getUser := apitest.NewMock().
 		Get("http://localhost:8080").
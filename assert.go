@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"reflect"
+	"regexp"
 	"runtime"
 	"strings"
 	"time"
@@ -341,6 +342,96 @@ func (n NoopVerifier) NoError(t TestingT, err error, msgAndArgs ...interface{})
 	return true
 }
 
+// recordingVerifier delegates to the wrapped verifier and adds the outcome of each assertion to the recorder
+type recordingVerifier struct {
+	name     string
+	verifier Verifier
+	recorder *Recorder
+}
+
+var _ Verifier = recordingVerifier{}
+
+// Equal records the assertion and delegates to the wrapped verifier
+func (v recordingVerifier) Equal(t TestingT, expected, actual interface{}, msgAndArgs ...interface{}) bool {
+	v.record(objectsAreEqual(expected, actual), expected, actual, msgAndArgs)
+	return v.verifier.Equal(t, expected, actual, msgAndArgs...)
+}
+
+// True records the assertion and delegates to the wrapped verifier
+func (v recordingVerifier) True(t TestingT, value bool, msgAndArgs ...interface{}) bool {
+	v.record(value, true, value, msgAndArgs)
+	return v.verifier.True(t, value, msgAndArgs...)
+}
+
+// JSONEq records the assertion and delegates to the wrapped verifier
+func (v recordingVerifier) JSONEq(t TestingT, expected string, actual string, msgAndArgs ...interface{}) bool {
+	var expectedJSON, actualJSON interface{}
+	expectedErr := json.Unmarshal([]byte(expected), &expectedJSON)
+	actualErr := json.Unmarshal([]byte(actual), &actualJSON)
+	passed := expectedErr == nil && actualErr == nil && objectsAreEqual(expectedJSON, actualJSON)
+
+	assertion := v.newAssertion(passed, expected, actual, msgAndArgs)
+	if !passed && expectedErr == nil && actualErr == nil {
+		assertion.Diff = plainDiff(expectedJSON, actualJSON)
+	}
+	v.recorder.AddAssertion(assertion)
+
+	return v.verifier.JSONEq(t, expected, actual, msgAndArgs...)
+}
+
+// Fail records the failed assertion and delegates to the wrapped verifier
+func (v recordingVerifier) Fail(t TestingT, failureMessage string, msgAndArgs ...interface{}) bool {
+	assertion := v.newAssertion(false, nil, nil, msgAndArgs)
+	assertion.Message = failureMessage
+	v.recorder.AddAssertion(assertion)
+	return v.verifier.Fail(t, failureMessage, msgAndArgs...)
+}
+
+// NoError records the assertion and delegates to the wrapped verifier
+func (v recordingVerifier) NoError(t TestingT, err error, msgAndArgs ...interface{}) bool {
+	assertion := v.newAssertion(err == nil, nil, nil, msgAndArgs)
+	if err != nil {
+		assertion.Actual = err.Error()
+	}
+	v.recorder.AddAssertion(assertion)
+	return v.verifier.NoError(t, err, msgAndArgs...)
+}
+
+func (v recordingVerifier) record(passed bool, expected, actual interface{}, msgAndArgs []interface{}) {
+	assertion := v.newAssertion(passed, expected, actual, msgAndArgs)
+	if !passed {
+		assertion.Diff = plainDiff(expected, actual)
+	}
+	v.recorder.AddAssertion(assertion)
+}
+
+var ansiColorRegexp = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// plainDiff returns the diff without the heading and terminal colors so that it can be rendered in reports
+func plainDiff(expected, actual interface{}) string {
+	return ansiColorRegexp.ReplaceAllString(strings.TrimPrefix(diff(expected, actual), "\n\nDiff:\n"), "")
+}
+
+// newAssertion creates the recorded assertion. The failure message is only recorded if the assertion failed
+func (v recordingVerifier) newAssertion(passed bool, expected, actual interface{}, msgAndArgs []interface{}) Assertion {
+	assertion := Assertion{Name: v.name, Passed: passed}
+	if expected != nil {
+		assertion.Expected = fmt.Sprintf("%v", expected)
+	}
+	if actual != nil {
+		assertion.Actual = fmt.Sprintf("%v", actual)
+	}
+	if passed {
+		return assertion
+	}
+	for _, msg := range msgAndArgs {
+		if msgAsStr, ok := msg.(string); ok {
+			assertion.Message = msgAsStr
+		}
+	}
+	return assertion
+}
+
 // IsSuccess is a convenience function to assert on a range of happy path status codes
 var IsSuccess Assert = func(response *http.Response, request *http.Request) error {
 	if response.StatusCode >= 200 && response.StatusCode < 400 {
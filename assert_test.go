@@ -30,3 +30,14 @@ func TestApiTest_Assert_StatusCodes(t *testing.T) {
 		}
 	}
 }
+
+func TestRecordingVerifier_RecordsMessageOnlyOnFailure(t *testing.T) {
+	recorder := NewTestRecorder()
+	verifier := recordingVerifier{name: "Status code", verifier: NoopVerifier{}, recorder: recorder}
+
+	verifier.Equal(t, 200, 200, "Status code 200 not equal to 200")
+	verifier.Equal(t, 200, 404, "Status code 404 not equal to 200")
+
+	assert.Equal(t, "", recorder.Assertions[0].Message)
+	assert.Equal(t, "Status code 404 not equal to 200", recorder.Assertions[1].Message)
+}
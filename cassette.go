@@ -0,0 +1,310 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+)
+
+// CassetteMode defines whether a Cassette records real http interactions or replays previously recorded interactions
+type CassetteMode int
+
+const (
+	// CassetteAuto replays the cassette if the cassette file exists, otherwise interactions are recorded
+	CassetteAuto CassetteMode = iota
+	// CassetteRecord forwards outbound requests to the real network and saves the interactions to the cassette file
+	CassetteRecord
+	// CassetteReplay loads the cassette file and replays the saved interactions as mocks
+	CassetteReplay
+)
+
+// Fields of a recorded request that are used to match outbound requests when a cassette is replayed
+const (
+	CassetteMatchMethod  = "method"
+	CassetteMatchURL     = "url"
+	CassetteMatchQuery   = "query"
+	CassetteMatchHeaders = "headers"
+	CassetteMatchBody    = "body"
+)
+
+// CassetteRedacted replaces the values of redacted request headers in the cassette file
+const CassetteRedacted = "[REDACTED]"
+
+var defaultCassetteRedact = []string{
+	"Authorization",
+	"Proxy-Authorization",
+	"Cookie",
+	"X-Api-Key",
+	"Api-Key",
+}
+
+var defaultCassetteMatchOn = []string{
+	CassetteMatchMethod,
+	CassetteMatchURL,
+	CassetteMatchQuery,
+	CassetteMatchBody,
+}
+
+// Cassette records outbound http interactions made by the system under test to a file. The saved interactions are
+// replayed as mocks in subsequent runs, removing the need to hand write mocks for third party APIs
+type Cassette struct {
+	path         string
+	mode         CassetteMode
+	matchOn      []string
+	redact       []string
+	interactions []CassetteInteraction
+	mu           sync.Mutex
+}
+
+// CassetteInteraction is a recorded http request and response pair
+type CassetteInteraction struct {
+	Request  CassetteRequest  `json:"request"`
+	Response CassetteResponse `json:"response"`
+}
+
+// CassetteRequest is the request side of a recorded interaction
+type CassetteRequest struct {
+	Method  string              `json:"method"`
+	URL     string              `json:"url"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    string              `json:"body,omitempty"`
+}
+
+// CassetteResponse is the response side of a recorded interaction
+type CassetteResponse struct {
+	Status  int                 `json:"status"`
+	Headers map[string][]string `json:"headers,omitempty"`
+	Body    string              `json:"body,omitempty"`
+}
+
+type cassetteFile struct {
+	Interactions []CassetteInteraction `json:"interactions"`
+}
+
+// NewCassette creates a new cassette stored at the given path. By default the cassette is replayed if the file
+// exists, otherwise the interactions are recorded. The Authorization, Proxy-Authorization, Cookie, X-Api-Key and
+// Api-Key headers and the Set-Cookie response header are redacted
+func NewCassette(path string) *Cassette {
+	return &Cassette{
+		path:    path,
+		mode:    CassetteAuto,
+		matchOn: defaultCassetteMatchOn,
+		redact:  defaultCassetteRedact,
+	}
+}
+
+// Record forces the cassette to record, overwriting the existing cassette file
+func (c *Cassette) Record() *Cassette {
+	c.mode = CassetteRecord
+	return c
+}
+
+// Replay forces the cassette to replay. The test fails if the cassette file does not exist
+func (c *Cassette) Replay() *Cassette {
+	c.mode = CassetteReplay
+	return c
+}
+
+// Mode sets the cassette mode
+func (c *Cassette) Mode(mode CassetteMode) *Cassette {
+	c.mode = mode
+	return c
+}
+
+// MatchOn defines the fields of the recorded requests used to match outbound requests during replay.
+// Defaults to CassetteMatchMethod, CassetteMatchURL, CassetteMatchQuery and CassetteMatchBody
+func (c *Cassette) MatchOn(fields ...string) *Cassette {
+	c.matchOn = fields
+	return c
+}
+
+// Redact adds headers whose values are replaced with CassetteRedacted in the requests and responses when the
+// interactions are recorded, e.g. headers that contain credentials. Redacted headers are not matched during replay
+func (c *Cassette) Redact(headers ...string) *Cassette {
+	c.redact = append(append([]string(nil), c.redact...), headers...)
+	return c
+}
+
+// Interactions returns the interactions recorded by or loaded into the cassette
+func (c *Cassette) Interactions() []CassetteInteraction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]CassetteInteraction(nil), c.interactions...)
+}
+
+func (c *Cassette) isRecording() bool {
+	if c.mode == CassetteAuto {
+		_, err := os.Stat(c.path)
+		return os.IsNotExist(err)
+	}
+	return c.mode == CassetteRecord
+}
+
+// Mocks loads the cassette file and returns a mock for each of the recorded interactions
+func (c *Cassette) Mocks() ([]*Mock, error) {
+	data, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file cassetteFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", c.path, err)
+	}
+
+	c.mu.Lock()
+	c.interactions = file.Interactions
+	c.mu.Unlock()
+
+	var mocks []*Mock
+	for _, interaction := range file.Interactions {
+		mock, err := interaction.toMock(c.matchOn)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load cassette %s: %w", c.path, err)
+		}
+		mocks = append(mocks, mock)
+	}
+	return mocks, nil
+}
+
+func (c *Cassette) record(req *http.Request, res *http.Response) {
+	interaction := CassetteInteraction{
+		Request: CassetteRequest{
+			Method:  req.Method,
+			URL:     req.URL.String(),
+			Headers: redactHeaders(req.Header, c.redact),
+		},
+		Response: CassetteResponse{
+			Status:  res.StatusCode,
+			Headers: redactHeaders(res.Header, append([]string{"Set-Cookie"}, c.redact...)),
+		},
+	}
+
+	if req.Body != nil {
+		body, _ := ioutil.ReadAll(req.Body)
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		interaction.Request.Body = string(body)
+	}
+
+	if res.Body != nil {
+		body, _ := ioutil.ReadAll(res.Body)
+		res.Body = ioutil.NopCloser(bytes.NewReader(body))
+		interaction.Response.Body = string(body)
+	}
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, interaction)
+	c.mu.Unlock()
+}
+
+func redactHeaders(header http.Header, keys []string) map[string][]string {
+	if header == nil {
+		return nil
+	}
+	redacted := make(map[string][]string, len(header))
+	for key, values := range header {
+		redacted[key] = append([]string(nil), values...)
+	}
+	for _, key := range keys {
+		key = textproto.CanonicalMIMEHeaderKey(key)
+		if values, ok := redacted[key]; ok {
+			for i := range values {
+				values[i] = CassetteRedacted
+			}
+		}
+	}
+	return redacted
+}
+
+func (c *Cassette) save() error {
+	c.mu.Lock()
+	file := cassetteFile{Interactions: c.interactions}
+	c.mu.Unlock()
+
+	if file.Interactions == nil {
+		file.Interactions = []CassetteInteraction{}
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(c.path), os.ModePerm); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(c.path, data, 0644)
+}
+
+func (i CassetteInteraction) toMock(matchOn []string) (*Mock, error) {
+	u, err := url.Parse(i.Request.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	mock := NewMock()
+	mock.request.url = &url.URL{}
+	for _, field := range matchOn {
+		switch field {
+		case CassetteMatchMethod:
+			mock.request.method = i.Request.Method
+		case CassetteMatchURL:
+			mock.request.url = &url.URL{Scheme: u.Scheme, Host: u.Host, Path: u.Path}
+		case CassetteMatchQuery:
+			query := u.Query()
+			for _, key := range sortedKeys(query) {
+				for _, value := range query[key] {
+					mock.request.Query(key, exactly(value))
+				}
+			}
+		case CassetteMatchHeaders:
+			for _, key := range sortedKeys(i.Request.Headers) {
+				for _, value := range i.Request.Headers[key] {
+					if value == CassetteRedacted {
+						continue
+					}
+					mock.request.Header(key, exactly(value))
+				}
+			}
+		case CassetteMatchBody:
+			mock.request.Body(i.Request.Body)
+		default:
+			return nil, fmt.Errorf("unknown cassette match field '%s'", field)
+		}
+	}
+
+	response := mock.request.RespondWith().
+		Status(i.Response.Status).
+		Body(i.Response.Body)
+	for key, values := range i.Response.Headers {
+		for _, value := range values {
+			if value == CassetteRedacted {
+				continue
+			}
+			response.Header(key, value)
+		}
+	}
+	return response.End(), nil
+}
+
+func exactly(value string) string {
+	return "^" + regexp.QuoteMeta(value) + "$"
+}
+
+func sortedKeys(m map[string][]string) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
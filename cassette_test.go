@@ -0,0 +1,210 @@
+package apitest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCassette_RecordsAndReplaysInteractions(t *testing.T) {
+	upstreamCalls := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamCalls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"id": "%s"}`, r.URL.Query().Get("id"))))
+	}))
+	path := filepath.Join(t.TempDir(), "cassettes", "user.json")
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		res, err := http.Get(upstream.URL + "/user?id=" + r.URL.Query().Get("id"))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := ioutil.ReadAll(res.Body)
+		_, _ = w.Write(body)
+	}
+
+	New().
+		Cassette(NewCassette(path)).
+		HandlerFunc(handler).
+		Get("/").
+		Query("id", "1").
+		Expect(t).
+		Body(`{"id": "1"}`).
+		End()
+
+	assert.Equal(t, 1, upstreamCalls)
+	var recorded cassetteFile
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, &recorded))
+	assert.Equal(t, 1, len(recorded.Interactions))
+	assert.Equal(t, http.MethodGet, recorded.Interactions[0].Request.Method)
+	assert.Equal(t, upstream.URL+"/user?id=1", recorded.Interactions[0].Request.URL)
+	assert.Equal(t, http.StatusOK, recorded.Interactions[0].Response.Status)
+	assert.Equal(t, `{"id": "1"}`, recorded.Interactions[0].Response.Body)
+
+	upstream.Close()
+
+	New().
+		Cassette(NewCassette(path)).
+		HandlerFunc(handler).
+		Get("/").
+		Query("id", "1").
+		Expect(t).
+		Body(`{"id": "1"}`).
+		End()
+
+	assert.Equal(t, 1, upstreamCalls)
+}
+
+func TestCassette_ReplayMatchesOnQueryExactly(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "user.json")
+	cassette := NewCassette(path)
+	cassette.interactions = []CassetteInteraction{{
+		Request:  CassetteRequest{Method: http.MethodGet, URL: "http://example.com/user?id=1"},
+		Response: CassetteResponse{Status: http.StatusOK, Body: "user 1"},
+	}}
+	assert.NoError(t, cassette.save())
+
+	reset := NewStandaloneMocks().Cassette(NewCassette(path).Replay()).End()
+	defer reset()
+
+	_, err := http.Get("http://example.com/user?id=10")
+	assert.True(t, err != nil)
+
+	res, err := http.Get("http://example.com/user?id=1")
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, "user 1", string(body))
+}
+
+func TestCassette_MatchOnMethodOnly(t *testing.T) {
+	interaction := CassetteInteraction{
+		Request:  CassetteRequest{Method: http.MethodPost, URL: "http://example.com/user", Body: `{"a": 1}`},
+		Response: CassetteResponse{Status: http.StatusCreated},
+	}
+
+	mock, err := interaction.toMock([]string{CassetteMatchMethod})
+
+	assert.NoError(t, err)
+	req := httptest.NewRequest(http.MethodPost, "http://other.com/path", strings.NewReader(`{"b": 2}`))
+	assert.Equal(t, 0, len(mock.Matches(req)))
+}
+
+func TestCassette_ErrorsOnUnknownMatchField(t *testing.T) {
+	interaction := CassetteInteraction{Request: CassetteRequest{Method: http.MethodGet, URL: "http://example.com"}}
+
+	_, err := interaction.toMock([]string{"unknown"})
+
+	assert.Equal(t, "unknown cassette match field 'unknown'", err.Error())
+}
+
+func TestCassette_ReplayFailsIfCassetteMissing(t *testing.T) {
+	_, err := NewCassette(filepath.Join(t.TempDir(), "missing.json")).Replay().Mocks()
+
+	assert.True(t, err != nil)
+}
+
+func TestCassette_RedactsCredentials(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "secret-session"})
+		_, _ = w.Write([]byte("jon"))
+	}))
+	defer upstream.Close()
+	path := filepath.Join(t.TempDir(), "user.json")
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		req, _ := http.NewRequest(http.MethodGet, upstream.URL+"/user", nil)
+		req.Header.Set("Authorization", r.Header.Get("Authorization"))
+		req.Header.Set("X-Session", "abc")
+		req.Header.Set("X-Request-Id", "1")
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		body, _ := ioutil.ReadAll(res.Body)
+		_, _ = w.Write(body)
+	}
+
+	New().
+		Cassette(NewCassette(path).Redact("X-Session")).
+		HandlerFunc(handler).
+		Get("/").
+		Header("Authorization", "Bearer secret-token").
+		Expect(t).
+		Body("jon").
+		End()
+
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.True(t, !strings.Contains(string(data), "secret-token"))
+	var recorded cassetteFile
+	assert.NoError(t, json.Unmarshal(data, &recorded))
+	assert.Equal(t, []string{CassetteRedacted}, recorded.Interactions[0].Request.Headers["Authorization"])
+	assert.Equal(t, []string{CassetteRedacted}, recorded.Interactions[0].Request.Headers["X-Session"])
+	assert.Equal(t, []string{"1"}, recorded.Interactions[0].Request.Headers["X-Request-Id"])
+	assert.Equal(t, []string{CassetteRedacted}, recorded.Interactions[0].Response.Headers["Set-Cookie"])
+	assert.True(t, !strings.Contains(string(data), "secret-session"))
+
+	New().
+		Cassette(NewCassette(path).Replay().MatchOn(CassetteMatchMethod, CassetteMatchURL, CassetteMatchHeaders)).
+		HandlerFunc(handler).
+		Get("/").
+		Header("Authorization", "Bearer other-token").
+		Expect(t).
+		Body("jon").
+		End()
+}
+
+func TestCassette_RecordsAndReplaysRequestBodies(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		_, _ = w.Write([]byte("created " + string(body)))
+	}))
+	path := filepath.Join(t.TempDir(), "users.json")
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		for _, name := range []string{"jon", "jan"} {
+			res, err := http.Post(upstream.URL+"/users", "application/json", strings.NewReader(`{"name":"`+name+`"}`))
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			body, _ := ioutil.ReadAll(res.Body)
+			_, _ = w.Write(append(body, ';'))
+		}
+	}
+
+	New().
+		Cassette(NewCassette(path)).
+		HandlerFunc(handler).
+		Get("/").
+		Expect(t).
+		Body(`created {"name":"jon"};created {"name":"jan"};`).
+		End()
+
+	var recorded cassetteFile
+	data, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, &recorded))
+	assert.Equal(t, `{"name":"jon"}`, recorded.Interactions[0].Request.Body)
+	assert.Equal(t, `{"name":"jan"}`, recorded.Interactions[1].Request.Body)
+	upstream.Close()
+
+	recorded.Interactions[0], recorded.Interactions[1] = recorded.Interactions[1], recorded.Interactions[0]
+	data, _ = json.Marshal(recorded)
+	assert.NoError(t, ioutil.WriteFile(path, data, 0644))
+
+	New().
+		Cassette(NewCassette(path)).
+		HandlerFunc(handler).
+		Get("/").
+		Expect(t).
+		Body(`created {"name":"jon"};created {"name":"jan"};`).
+		End()
+}
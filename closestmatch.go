@@ -0,0 +1,226 @@
+package apitest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+)
+
+// closestMatchesDescribed is the number of closest matching mocks described field by field when a request does not
+// match any mocks. The remaining mocks are summarised on a single line each
+const closestMatchesDescribed = 3
+
+const maxComparisonValueLength = 40
+
+// mockCandidate is a mock that did not match the received request
+type mockCandidate struct {
+	mock   *Mock
+	passed int
+}
+
+// configuredMatchers reports, for each of the defaultMatchers, whether the mock request sets the fields checked by
+// the matcher. The default matchers pass for fields which are not set, so only the configured ones are counted when
+// ranking how closely a request matched a mock
+var configuredMatchers = []func(*MockRequest) bool{
+	func(r *MockRequest) bool { return r.url != nil && r.url.Path != "" },
+	func(r *MockRequest) bool { return r.url != nil && r.url.Host != "" },
+	func(r *MockRequest) bool { return r.url != nil && r.url.Scheme != "" },
+	func(r *MockRequest) bool { return r.method != "" },
+	func(r *MockRequest) bool { return len(r.headers) > 0 },
+	func(r *MockRequest) bool { return r.basicAuthUsername != "" },
+	func(r *MockRequest) bool { return len(r.headerPresent) > 0 },
+	func(r *MockRequest) bool { return len(r.headerNotPresent) > 0 },
+	func(r *MockRequest) bool { return len(r.query) > 0 },
+	func(r *MockRequest) bool { return len(r.queryPresent) > 0 },
+	func(r *MockRequest) bool { return len(r.queryNotPresent) > 0 },
+	func(r *MockRequest) bool { return len(r.formData) > 0 },
+	func(r *MockRequest) bool { return len(r.formDataPresent) > 0 },
+	func(r *MockRequest) bool { return len(r.formDataNotPresent) > 0 },
+	func(r *MockRequest) bool { return r.body != "" },
+	func(r *MockRequest) bool { return r.bodyRegexp != "" },
+	func(r *MockRequest) bool { return len(r.cookie) > 0 },
+	func(r *MockRequest) bool { return len(r.cookiePresent) > 0 },
+	func(r *MockRequest) bool { return len(r.cookieNotPresent) > 0 },
+}
+
+// configures returns true if the matcher at the given index checks a field set by the mock request. Matchers added
+// with AddMatcher are always counted
+func (r *MockRequest) configures(i int) bool {
+	return i >= len(configuredMatchers) || configuredMatchers[i](r)
+}
+
+// checks returns the number of matchers which check fields set by the mock request
+func (r *MockRequest) checks() int {
+	checks := 0
+	for i := range r.matchers {
+		if r.configures(i) {
+			checks++
+		}
+	}
+	return checks
+}
+
+// receivedRequest is a copy of a request that did not match any mocks, made when the request was received so that
+// it can be described after its body has been consumed
+type receivedRequest struct {
+	method string
+	url    *url.URL
+	host   string
+	header http.Header
+	body   *string
+}
+
+func newReceivedRequest(req *http.Request) *receivedRequest {
+	u := *req.URL
+	received := &receivedRequest{
+		method: req.Method,
+		url:    &u,
+		host:   req.Host,
+		header: req.Header.Clone(),
+	}
+	if req.Body != nil {
+		data, err := ioutil.ReadAll(req.Body)
+		if err == nil {
+			req.Body = ioutil.NopCloser(bytes.NewReader(data))
+			body := string(data)
+			received.body = &body
+		}
+	}
+	return received
+}
+
+func (r *receivedRequest) String() string {
+	return fmt.Sprintf("%s %s", r.method, r.url)
+}
+
+// newRequest recreates the received request so that the mock matchers can be run against it
+func (r *receivedRequest) newRequest() *http.Request {
+	req := &http.Request{Method: r.method, URL: r.url, Host: r.host, Header: r.header}
+	if r.body != nil {
+		req.Body = ioutil.NopCloser(strings.NewReader(*r.body))
+	}
+	return req
+}
+
+// compare returns a table comparing the fields of the request to the expectations of the mock and a diff of the
+// bodies if they do not match
+func (r *receivedRequest) compare(spec *MockRequest) string {
+	type row struct {
+		field, expected, received string
+		matched                   bool
+	}
+	matches := func(matcher Matcher) bool {
+		return matcher(r.newRequest(), spec) == nil
+	}
+
+	method := spec.method
+	if method == "" {
+		method = "ANY"
+	}
+	rows := []row{{"method", method, r.method, matches(methodMatcher)}}
+	if spec.url != nil {
+		if spec.url.Scheme != "" {
+			rows = append(rows, row{"scheme", spec.url.Scheme, r.url.Scheme, matches(schemeMatcher)})
+		}
+		if spec.url.Host != "" {
+			host := r.host
+			if host == "" {
+				host = r.url.Host
+			}
+			rows = append(rows, row{"host", spec.url.Host, host, matches(hostMatcher)})
+		}
+		rows = append(rows, row{"path", spec.url.Path, r.url.Path, matches(pathMatcher)})
+	}
+
+	query := r.url.Query()
+	for _, key := range sortedKeys(spec.query) {
+		rows = append(rows, row{"query " + key, strings.Join(spec.query[key], ", "), strings.Join(query[key], ", "),
+			valuesMatch(spec.query[key], query[key])})
+	}
+	for _, key := range sortedKeys(spec.headers) {
+		rows = append(rows, row{"header " + key, strings.Join(spec.headers[key], ", "), strings.Join(r.header.Values(key), ", "),
+			valuesMatch(spec.headers[key], r.header.Values(key))})
+	}
+
+	bodyMatched := true
+	if spec.body != "" {
+		bodyMatched = matches(bodyMatcher)
+		received := "<none>"
+		if r.body != nil {
+			received = *r.body
+		}
+		rows = append(rows, row{"body", spec.body, received, bodyMatched})
+	}
+
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "   field\texpected\treceived")
+	for _, row := range rows {
+		mark := " "
+		if !row.matched {
+			mark = "✗"
+		}
+		_, _ = fmt.Fprintf(w, " %s %s\t%s\t%s\n", mark, row.field, truncate(row.expected), truncate(row.received))
+	}
+	_ = w.Flush()
+
+	if !bodyMatched && r.body != nil {
+		buf.WriteString(strings.TrimPrefix(diff(indentJSON(spec.body), indentJSON(*r.body)), "\n\n"))
+	}
+	return buf.String()
+}
+
+// describe returns the method and url of the mock request
+func (r *MockRequest) describe() string {
+	method := r.method
+	if method == "" {
+		method = "ANY"
+	}
+	if r.url == nil {
+		return method
+	}
+	return fmt.Sprintf("%s %s", method, r.url)
+}
+
+// valuesMatch returns true if each expected regular expression matches one of the received values
+func valuesMatch(expected, received []string) bool {
+	for _, value := range expected {
+		found := false
+		for _, field := range received {
+			if matched, err := regexp.MatchString(value, field); err == nil && matched {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func truncate(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if value == "" {
+		return "<none>"
+	}
+	if runes := []rune(value); len(runes) > maxComparisonValueLength {
+		return string(runes[:maxComparisonValueLength-3]) + "..."
+	}
+	return value
+}
+
+// indentJSON formats JSON so that bodies are compared line by line, returning other content unchanged
+func indentJSON(body string) string {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, []byte(body), "", "  "); err != nil {
+		return body
+	}
+	return buf.String()
+}
@@ -0,0 +1,121 @@
+package apitest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestClosestMatch_OrdersMocksByClosestMatch(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/users?page=2", strings.NewReader(`{"name": "jon"}`))
+	req.Header.Set("Authorization", "Bearer abc")
+
+	_, err := matches(req, []*Mock{
+		NewMock().Get("http://example.com/posts").Header("X-Tenant", "a").RespondWith().End(),
+		NewMock().Post("http://example.com/users").Query("page", "1").Header("Authorization", "Bearer abc").RespondWith().End(),
+		NewMock().Delete("http://example.com/users/1").RespondWith().End(),
+		NewMock().Put("http://example.com/users").RespondWith().End(),
+		NewMock().Post("http://example.com/orders").Header("X-Tenant", "a").RespondWith().End(),
+	}, nil)
+
+	assert.Equal(t, "received request did not match any mocks\n\n"+
+		"POST http://example.com/users?page=2\n\n"+
+		"Mock 2 POST http://example.com/users passed 5 of 6 checks:\n"+
+		"   field                 expected     received\n"+
+		"   method                POST         POST\n"+
+		"   scheme                http         http\n"+
+		"   host                  example.com  example.com\n"+
+		"   path                  /users       /users\n"+
+		" ✗ query page            1            2\n"+
+		"   header Authorization  Bearer abc   Bearer abc\n"+
+		"• not all of received query params map[page:[2]] matched expected mock query params map[page:[1]]\n\n"+
+		"Mock 4 PUT http://example.com/users passed 3 of 4 checks:\n"+
+		"   field   expected     received\n"+
+		" ✗ method  PUT          POST\n"+
+		"   scheme  http         http\n"+
+		"   host    example.com  example.com\n"+
+		"   path    /users       /users\n"+
+		"• received method POST did not match mock method PUT\n\n"+
+		"Mock 5 POST http://example.com/orders passed 3 of 5 checks:\n"+
+		"   field            expected     received\n"+
+		"   method           POST         POST\n"+
+		"   scheme           http         http\n"+
+		"   host             example.com  example.com\n"+
+		" ✗ path             /orders      /users\n"+
+		" ✗ header X-Tenant  a            <none>\n"+
+		"• received path /users did not match mock path /orders\n"+
+		"• not all of received headers map[Authorization:[Bearer abc]] matched expected mock headers map[X-Tenant:[a]]\n\n"+
+		"Other mocks:\n"+
+		"• Mock 3 DELETE http://example.com/users/1 passed 2 of 4 checks\n"+
+		"• Mock 1 GET http://example.com/posts passed 2 of 5 checks\n\n",
+		err.Error())
+}
+
+func TestClosestMatch_CountsConfiguredChecks(t *testing.T) {
+	assert.Equal(t, len(defaultMatchers), len(configuredMatchers))
+
+	mock := NewMock().
+		Post("/users").
+		Header("Authorization", "Bearer abc").
+		AddMatcher(func(*http.Request, *MockRequest) error { return nil }).
+		RespondWith().
+		End()
+	req := httptest.NewRequest(http.MethodPost, "/users", nil)
+
+	errs, passed := mock.match(req)
+
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, 3, passed)
+	assert.Equal(t, 4, mock.request.checks())
+}
+
+func TestClosestMatch_DiffsBodies(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(`{"name": "jon", "age": 20}`))
+
+	_, err := matches(req, []*Mock{
+		NewMock().Post("/users").Body(`{"name": "jan", "age": 20}`).RespondWith().End(),
+	}, nil)
+
+	message := err.Error()
+	assert.True(t, strings.Contains(message, " ✗ body    {\"name\": \"jan\", \"age\": 20}  {\"name\": \"jon\", \"age\": 20}\n"))
+	assert.True(t, strings.Contains(message, "-  \"name\": \"jan\",\n"))
+	assert.True(t, strings.Contains(message, "+  \"name\": \"jon\",\n"))
+	assert.True(t, strings.HasSuffix(message, "\n• received body did not match expected mock body\n\n"))
+}
+
+func TestClosestMatch_UnmatchedRequestInReport(t *testing.T) {
+	failed := HttpRequest{
+		Source:    SystemUnderTestDefaultName,
+		Target:    "example.com",
+		Value:     httptest.NewRequest(http.MethodGet, "http://example.com/user", nil),
+		Timestamp: time.Now(),
+		Error:     "received request did not match any mocks",
+	}
+	recorder := NewTestRecorder().
+		AddHttpRequest(HttpRequest{Source: ConsumerDefaultName, Target: SystemUnderTestDefaultName, Value: httptest.NewRequest(http.MethodGet, "/", nil)}).
+		AddHttpRequest(failed).
+		AddHttpResponse(HttpResponse{Source: SystemUnderTestDefaultName, Target: ConsumerDefaultName, Value: &http.Response{StatusCode: http.StatusInternalServerError}})
+
+	model, err := newHTMLTemplateModel(recorder)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []int{2}, model.FailedRows)
+	assert.True(t, strings.Contains(model.WebSequenceDSL, "(2) GET /user ✗ failed"))
+	assert.Equal(t, "received request did not match any mocks", model.LogEntries[1].Error)
+	assert.Equal(t, "bg-danger", newWaterfall(recorder.Events, nil).Lanes[0][0].Class)
+}
+
+func TestClosestMatch_ObserversReceiveMatchError(t *testing.T) {
+	var observed error
+	observe := func(res *http.Response, req *http.Request, a *APITest) {
+		observed = mockMatchError(req)
+	}
+	transport := newTransport([]*Mock{NewMock().Get("http://example.com/user").RespondWith().End()}, nil, false, false, []Observe{observe}, nil)
+
+	_, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "http://example.com/posts", nil))
+
+	assert.True(t, err != nil)
+	assert.Equal(t, err, observed)
+}
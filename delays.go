@@ -0,0 +1,102 @@
+package apitest
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// delayDistribution samples mock response delays using a seeded random source so that test runs are repeatable
+type delayDistribution struct {
+	mu     sync.Mutex
+	seed   int64
+	rand   *rand.Rand
+	sample func(*rand.Rand) time.Duration
+}
+
+func newDelayDistribution(seed int64, sample func(*rand.Rand) time.Duration) *delayDistribution {
+	return &delayDistribution{seed: seed, rand: rand.New(rand.NewSource(seed)), sample: sample}
+}
+
+// copy returns a distribution with a new random source using the same seed, which restarts the sequence of delays
+func (d *delayDistribution) copy() *delayDistribution {
+	if d == nil {
+		return nil
+	}
+	return newDelayDistribution(d.seed, d.sample)
+}
+
+func (d *delayDistribution) next() time.Duration {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	delay := d.sample(d.rand)
+	if delay < 0 {
+		return 0
+	}
+	return delay
+}
+
+// UniformDelay delays the response by a random duration between min and max.
+// APITest::EnableMockResponseDelay must be set for this to take effect
+func (r *MockResponse) UniformDelay(min, max time.Duration, seed int64) *MockResponse {
+	return r.setDelayDistribution(newDelayDistribution(seed, func(rnd *rand.Rand) time.Duration {
+		if max <= min {
+			return min
+		}
+		return min + time.Duration(rnd.Int63n(int64(max-min)+1))
+	}))
+}
+
+// NormalDelay delays the response by a random duration from a normal distribution with the given mean and
+// standard deviation. Negative samples are treated as no delay.
+// APITest::EnableMockResponseDelay must be set for this to take effect
+func (r *MockResponse) NormalDelay(mean, stddev time.Duration, seed int64) *MockResponse {
+	return r.setDelayDistribution(newDelayDistribution(seed, func(rnd *rand.Rand) time.Duration {
+		return mean + time.Duration(rnd.NormFloat64()*float64(stddev))
+	}))
+}
+
+// LogNormalDelay delays the response by a random duration from a log-normal distribution with the given median
+// and shape sigma, which models the long tail of real network latency.
+// APITest::EnableMockResponseDelay must be set for this to take effect
+func (r *MockResponse) LogNormalDelay(median time.Duration, sigma float64, seed int64) *MockResponse {
+	return r.setDelayDistribution(newDelayDistribution(seed, func(rnd *rand.Rand) time.Duration {
+		return time.Duration(float64(median) * math.Exp(sigma*rnd.NormFloat64()))
+	}))
+}
+
+func (r *MockResponse) setDelayDistribution(distribution *delayDistribution) *MockResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.delayDistribution = distribution
+	return r
+}
+
+// delay returns the delay of the response for the current call
+func (r *MockResponse) delay() time.Duration {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.delayDistribution != nil {
+		return r.delayDistribution.next()
+	}
+	return time.Duration(r.fixedDelayMillis) * time.Millisecond
+}
+
+// sleepContext waits for the delay, returning the context error if the context ends first
+func sleepContext(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
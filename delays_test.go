@@ -0,0 +1,84 @@
+package apitest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDelays_AbortWhenRequestContextEnds(t *testing.T) {
+	getUser := NewMock().Get("http://localhost:8080/user").RespondWith().FixedDelay(1000).End()
+	reset := NewStandaloneMocks(getUser).EnableMockResponseDelay().End()
+	defer reset()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://localhost:8080/user", nil)
+	started := time.Now()
+
+	_, err := http.DefaultClient.Do(req)
+
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.True(t, time.Since(started) < 500*time.Millisecond)
+	assert.Equal(t, 1, len(getUser.Calls()))
+}
+
+func TestDelays_AbortWhenClientTimesOut(t *testing.T) {
+	cli := &http.Client{Timeout: 20 * time.Millisecond}
+	getUser := NewMock().Get("http://localhost:8080/user").RespondWith().FixedDelay(1000).End()
+	reset := NewStandaloneMocks(getUser).HttpClient(cli).EnableMockResponseDelay().End()
+	defer reset()
+	started := time.Now()
+
+	_, err := cli.Get("http://localhost:8080/user")
+
+	assert.True(t, err != nil)
+	assert.True(t, time.Since(started) < 500*time.Millisecond)
+}
+
+func TestDelays_Distributions(t *testing.T) {
+	tests := map[string]struct {
+		response func() *MockResponse
+		min      time.Duration
+		max      time.Duration
+	}{
+		"uniform": {func() *MockResponse {
+			return NewMock().Get("/").RespondWith().UniformDelay(10*time.Millisecond, 20*time.Millisecond, 1)
+		}, 10 * time.Millisecond, 20 * time.Millisecond},
+		"normal": {func() *MockResponse {
+			return NewMock().Get("/").RespondWith().NormalDelay(100*time.Millisecond, 10*time.Millisecond, 1)
+		}, 0, 200 * time.Millisecond},
+		"log normal": {func() *MockResponse {
+			return NewMock().Get("/").RespondWith().LogNormalDelay(50*time.Millisecond, 0.5, 1)
+		}, 0, time.Second},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			first, second := test.response(), test.response()
+			for i := 0; i < 20; i++ {
+				delay := first.delay()
+				assert.True(t, delay >= test.min && delay <= test.max)
+				assert.Equal(t, delay, second.delay())
+			}
+		})
+	}
+}
+
+func TestDelays_NegativeSamplesAreNoDelay(t *testing.T) {
+	response := NewMock().Get("/").RespondWith().NormalDelay(-time.Second, time.Millisecond, 1)
+
+	assert.Equal(t, time.Duration(0), response.delay())
+}
+
+func TestDelays_MockServerAbortsWhenClientTimesOut(t *testing.T) {
+	server := NewMockServer(NewMock().Get("/user").RespondWith().LogNormalDelay(time.Second, 0.1, 1).End())
+	defer server.Close()
+	cli := &http.Client{Timeout: 20 * time.Millisecond}
+	started := time.Now()
+
+	_, err := cli.Get(server.URL + "/user")
+
+	assert.True(t, err != nil)
+	assert.True(t, time.Since(started) < 500*time.Millisecond)
+}
@@ -12,6 +12,7 @@ import (
 	"net/http/httputil"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -22,15 +23,38 @@ type (
 		Title          string
 		SubTitle       string
 		StatusCode     int
+		HasStatus      bool
 		BadgeClass     string
 		LogEntries     []logEntry
 		WebSequenceDSL string
+		FailedRows     []int
+		Waterfall      waterfall
+		Assertions     []Assertion
+		Passed         bool
 		MetaJSON       htmlTemplate.JS
 	}
 
+	waterfall struct {
+		Duration time.Duration
+		Lanes    [][]waterfallBar
+	}
+
+	waterfallBar struct {
+		Label       string
+		Class       string
+		LogIndex    int
+		Start       time.Time
+		End         time.Time
+		StartOffset time.Duration
+		EndOffset   time.Duration
+		Left        string
+		Width       string
+	}
+
 	logEntry struct {
 		Header    string
 		Body      string
+		Error     string
 		Timestamp time.Time
 	}
 
@@ -71,18 +95,8 @@ func (r *webSequenceDiagramDSL) addResponseRow(source string, target string, des
 }
 
 func (r *webSequenceDiagramDSL) addRow(operation, source string, target string, description string) {
-	if name, ok := r.meta["consumerName"]; ok {
-		if n, ok := name.(string); ok {
-			source = strings.ReplaceAll(source, ConsumerDefaultName, n)
-			target = strings.ReplaceAll(target, ConsumerDefaultName, n)
-		}
-	}
-	if name, ok := r.meta["systemUnderTestName"]; ok {
-		if n, ok := name.(string); ok {
-			source = strings.ReplaceAll(source, SystemUnderTestDefaultName, n)
-			target = strings.ReplaceAll(target, SystemUnderTestDefaultName, n)
-		}
-	}
+	source = participantName(source, r.meta)
+	target = participantName(target, r.meta)
 	r.count++
 	r.data.WriteString(fmt.Sprintf("%s%s%s: (%d) %s\n",
 		quoted(source),
@@ -93,6 +107,21 @@ func (r *webSequenceDiagramDSL) addRow(operation, source string, target string,
 	)
 }
 
+// participantName replaces the default consumer and system under test names with the names defined in the meta
+func participantName(name string, meta map[string]interface{}) string {
+	if consumerName, ok := meta["consumerName"]; ok {
+		if n, ok := consumerName.(string); ok {
+			name = strings.ReplaceAll(name, ConsumerDefaultName, n)
+		}
+	}
+	if sutName, ok := meta["systemUnderTestName"]; ok {
+		if n, ok := sutName.(string); ok {
+			name = strings.ReplaceAll(name, SystemUnderTestDefaultName, n)
+		}
+	}
+	return name
+}
+
 func (r *webSequenceDiagramDSL) toString() string {
 	return r.data.String()
 }
@@ -176,21 +205,30 @@ func badgeCSSClass(status int) string {
 }
 
 func newHTMLTemplateModel(r *Recorder) (htmlTemplateModel, error) {
-	if len(r.Events) == 0 {
+	if len(r.Events) == 0 && len(r.Assertions) == 0 {
 		return htmlTemplateModel{}, errors.New("no events are defined")
 	}
 	var logs []logEntry
+	var failedRows []int
 	webSequenceDiagram := &webSequenceDiagramDSL{meta: r.Meta}
 
 	for _, event := range r.Events {
 		switch v := event.(type) {
 		case HttpRequest:
 			httpReq := v.Value
-			webSequenceDiagram.addRequestRow(v.Source, v.Target, formatDiagramRequest(httpReq))
+			description := formatDiagramRequest(httpReq)
+			if v.Error != "" {
+				description += " ✗ failed"
+			}
+			webSequenceDiagram.addRequestRow(v.Source, v.Target, description)
+			if v.Error != "" {
+				failedRows = append(failedRows, webSequenceDiagram.count)
+			}
 			entry, err := newHTTPRequestLogEntry(httpReq)
 			if err != nil {
 				return htmlTemplateModel{}, err
 			}
+			entry.Error = v.Error
 			entry.Timestamp = v.Timestamp
 			logs = append(logs, entry)
 		case HttpResponse:
@@ -212,10 +250,9 @@ func newHTMLTemplateModel(r *Recorder) (htmlTemplateModel, error) {
 		}
 	}
 
+	// the reports of standalone mocks have no final response
 	status, err := r.ResponseStatus()
-	if err != nil {
-		return htmlTemplateModel{}, err
-	}
+	hasStatus := err == nil
 
 	jsonMeta, err := json.Marshal(r.Meta)
 	if err != nil {
@@ -224,15 +261,141 @@ func newHTMLTemplateModel(r *Recorder) (htmlTemplateModel, error) {
 
 	return htmlTemplateModel{
 		WebSequenceDSL: webSequenceDiagram.toString(),
+		FailedRows:     failedRows,
+		Waterfall:      newWaterfall(r.Events, r.Meta),
+		Assertions:     r.Assertions,
+		Passed:         r.Passed(),
 		LogEntries:     logs,
 		Title:          r.Title,
 		SubTitle:       r.SubTitle,
 		StatusCode:     status,
+		HasStatus:      hasStatus,
 		BadgeClass:     badgeCSSClass(status),
 		MetaJSON:       htmlTemplate.JS(jsonMeta),
 	}, nil
 }
 
+// newWaterfall pairs each request event with its response and lays the resulting bars out in lanes, so that
+// interactions which overlap in time are drawn side by side. A response is paired with the request it refers to
+// using http.Response.Request, otherwise with the oldest pending request flowing in the opposite direction between
+// the same participants. Requests without a response are drawn as instantaneous bars
+func newWaterfall(events []Event, meta map[string]interface{}) waterfall {
+	var bars []waterfallBar
+	pending := map[string][]int{}
+	requests := map[*http.Request]int{}
+
+	for i, event := range events {
+		switch v := event.(type) {
+		case HttpRequest:
+			class := "bg-info"
+			if v.Error != "" {
+				class = "bg-danger"
+			}
+			bars = append(bars, newWaterfallBar(i, v.Source, v.Target, formatDiagramRequest(v.Value), class, v.Timestamp, meta))
+			key := v.Source + "->" + v.Target
+			pending[key] = append(pending[key], len(bars)-1)
+			if v.Value != nil {
+				requests[v.Value] = len(bars) - 1
+			}
+		case MessageRequest:
+			bars = append(bars, newWaterfallBar(i, v.Source, v.Target, v.Header, "bg-warning", v.Timestamp, meta))
+			key := v.Source + "->" + v.Target
+			pending[key] = append(pending[key], len(bars)-1)
+		case HttpResponse:
+			bar := -1
+			if v.Value != nil && v.Value.Request != nil {
+				if index, ok := requests[v.Value.Request]; ok {
+					bar = index
+				}
+			}
+			completeWaterfallBar(bars, pending, v.Target+"->"+v.Source, bar, v.Timestamp)
+		case MessageResponse:
+			completeWaterfallBar(bars, pending, v.Target+"->"+v.Source, -1, v.Timestamp)
+		}
+	}
+
+	var timed []waterfallBar
+	for _, bar := range bars {
+		if !bar.Start.IsZero() {
+			timed = append(timed, bar)
+		}
+	}
+	if len(timed) == 0 {
+		return waterfall{}
+	}
+
+	first, last := timed[0].Start, timed[0].End
+	for _, bar := range timed {
+		if bar.Start.Before(first) {
+			first = bar.Start
+		}
+		if bar.End.After(last) {
+			last = bar.End
+		}
+	}
+	total := last.Sub(first)
+
+	sort.SliceStable(timed, func(i, j int) bool {
+		return timed[i].Start.Before(timed[j].Start)
+	})
+
+	var lanes [][]waterfallBar
+	for _, bar := range timed {
+		bar.StartOffset = bar.Start.Sub(first)
+		bar.EndOffset = bar.End.Sub(first)
+		bar.Left, bar.Width = "0", "100"
+		if total > 0 {
+			bar.Left = strconv.FormatFloat(float64(bar.StartOffset)/float64(total)*100, 'f', 2, 64)
+			bar.Width = strconv.FormatFloat(float64(bar.EndOffset-bar.StartOffset)/float64(total)*100, 'f', 2, 64)
+		}
+
+		lane := 0
+		for ; lane < len(lanes); lane++ {
+			previous := lanes[lane][len(lanes[lane])-1]
+			if !bar.Start.Before(previous.End) {
+				break
+			}
+		}
+		if lane == len(lanes) {
+			lanes = append(lanes, nil)
+		}
+		lanes[lane] = append(lanes[lane], bar)
+	}
+
+	return waterfall{Duration: total, Lanes: lanes}
+}
+
+func newWaterfallBar(logIndex int, source, target, description, class string, start time.Time, meta map[string]interface{}) waterfallBar {
+	if source == ConsumerDefaultName && target == SystemUnderTestDefaultName {
+		class = "bg-primary"
+	}
+	return waterfallBar{
+		Label:    fmt.Sprintf("%s -> %s: %s", participantName(source, meta), participantName(target, meta), description),
+		Class:    class,
+		LogIndex: logIndex,
+		Start:    start,
+		End:      start,
+	}
+}
+
+// completeWaterfallBar ends the given bar, or the oldest pending bar between the participants if the bar is negative
+func completeWaterfallBar(bars []waterfallBar, pending map[string][]int, key string, bar int, end time.Time) {
+	i := -1
+	for j, index := range pending[key] {
+		if bar < 0 || index == bar {
+			i = index
+			pending[key] = append(pending[key][:j:j], pending[key][j+1:]...)
+			break
+		}
+	}
+	if i < 0 {
+		return
+	}
+	if end.After(bars[i].Start) {
+		bars[i].End = end
+	}
+}
+
 func newHTTPRequestLogEntry(req *http.Request) (logEntry, error) {
 	reqHeader, err := httputil.DumpRequest(req, false)
 	if err != nil {
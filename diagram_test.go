@@ -9,6 +9,7 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestDiagram_BadgeCSSClass(t *testing.T) {
@@ -113,6 +114,7 @@ func TestNewHTMLTemplateModel_Success(t *testing.T) {
 	assert.Equal(t, "subTitle", model.SubTitle)
 	assert.Equal(t, template.JS(`{"host":"example.com","method":"GET","name":"some test","path":"/user"}`), model.MetaJSON)
 	assert.Equal(t, http.StatusNoContent, model.StatusCode)
+	assert.True(t, model.HasStatus)
 	assert.Equal(t, "badge badge-success", model.BadgeClass)
 	assert.True(t, strings.Contains(model.WebSequenceDSL, "GET /abcdef"))
 }
@@ -133,6 +135,96 @@ func aRecorder() *Recorder {
 		})
 }
 
+func TestNewHTMLTemplateModel_IncludesAssertions(t *testing.T) {
+	recorder := aRecorder().
+		AddAssertion(Assertion{Name: "Status code", Passed: true}).
+		AddAssertion(Assertion{Name: "Body", Passed: false, Expected: "a", Actual: "b"})
+
+	model, err := newHTMLTemplateModel(recorder)
+
+	assert.True(t, err == nil)
+	assert.Equal(t, false, model.Passed)
+	assert.Equal(t, 2, len(model.Assertions))
+}
+
+func TestSequenceDiagramFormatter_RendersAssertions(t *testing.T) {
+	fs := &FS{}
+	formatter := SequenceDiagramFormatter{storagePath: ".sequence", fs: fs}
+	recorder := aRecorder().
+		AddAssertion(Assertion{Name: "Status code", Passed: true}).
+		AddAssertion(Assertion{Name: "Body", Passed: false, Expected: "abc", Actual: "abd", Message: "body mismatch"})
+
+	formatter.Format(recorder)
+
+	html, err := ioutil.ReadFile(fs.CapturedCreateFile)
+	assert.NoError(t, err)
+	assert.True(t, strings.Contains(string(html), `<span class="badge badge-danger">FAILED</span>`))
+	assert.True(t, strings.Contains(string(html), `<strong>Body</strong>`))
+	assert.True(t, strings.Contains(string(html), `<code>abd</code>`))
+	assert.True(t, strings.Contains(string(html), `<div>body mismatch</div>`))
+}
+
+func TestNewWaterfall_PlacesConcurrentInteractionsInParallelLanes(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(millis int) time.Time { return start.Add(time.Duration(millis) * time.Millisecond) }
+	events := []Event{
+		HttpRequest{Source: ConsumerDefaultName, Target: SystemUnderTestDefaultName, Value: httptest.NewRequest(http.MethodGet, "/user", nil), Timestamp: at(0)},
+		HttpRequest{Source: SystemUnderTestDefaultName, Target: "a.com", Value: httptest.NewRequest(http.MethodGet, "http://a.com/a", nil), Timestamp: at(10)},
+		HttpRequest{Source: SystemUnderTestDefaultName, Target: "b.com", Value: httptest.NewRequest(http.MethodGet, "http://b.com/b", nil), Timestamp: at(20)},
+		HttpResponse{Source: "a.com", Target: SystemUnderTestDefaultName, Value: &http.Response{StatusCode: http.StatusOK}, Timestamp: at(40)},
+		HttpResponse{Source: "b.com", Target: SystemUnderTestDefaultName, Value: &http.Response{StatusCode: http.StatusOK}, Timestamp: at(50)},
+		MessageRequest{Source: SystemUnderTestDefaultName, Target: "sqlite", Header: "SQL Query", Timestamp: at(60)},
+		MessageResponse{Source: "sqlite", Target: SystemUnderTestDefaultName, Header: "SQL Result", Timestamp: at(70)},
+		HttpResponse{Source: SystemUnderTestDefaultName, Target: ConsumerDefaultName, Value: &http.Response{StatusCode: http.StatusOK}, Timestamp: at(100)},
+	}
+
+	w := newWaterfall(events, map[string]interface{}{"systemUnderTestName": "my-api"})
+
+	assert.Equal(t, 100*time.Millisecond, w.Duration)
+	assert.Equal(t, 3, len(w.Lanes))
+	assert.Equal(t, 1, len(w.Lanes[0]))
+	assert.Equal(t, "cli -> my-api: GET /user", w.Lanes[0][0].Label)
+	assert.Equal(t, "bg-primary", w.Lanes[0][0].Class)
+	assert.Equal(t, "100.00", w.Lanes[0][0].Width)
+	assert.Equal(t, 2, len(w.Lanes[1]))
+	assert.Equal(t, "my-api -> a.com: GET /a", w.Lanes[1][0].Label)
+	assert.Equal(t, 10*time.Millisecond, w.Lanes[1][0].StartOffset)
+	assert.Equal(t, 40*time.Millisecond, w.Lanes[1][0].EndOffset)
+	assert.Equal(t, "my-api -> sqlite: SQL Query", w.Lanes[1][1].Label)
+	assert.Equal(t, 5, w.Lanes[1][1].LogIndex)
+	assert.Equal(t, 1, len(w.Lanes[2]))
+	assert.Equal(t, "my-api -> b.com: GET /b", w.Lanes[2][0].Label)
+	assert.Equal(t, "20.00", w.Lanes[2][0].Left)
+	assert.Equal(t, "30.00", w.Lanes[2][0].Width)
+}
+
+func TestNewWaterfall_PairsResponsesWithTheirRequests(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(millis int) time.Time { return start.Add(time.Duration(millis) * time.Millisecond) }
+	slow := httptest.NewRequest(http.MethodGet, "http://a.com/slow", nil)
+	fast := httptest.NewRequest(http.MethodGet, "http://a.com/fast", nil)
+	events := []Event{
+		HttpRequest{Source: SystemUnderTestDefaultName, Target: "a.com", Value: slow, Timestamp: at(0)},
+		HttpRequest{Source: SystemUnderTestDefaultName, Target: "a.com", Value: fast, Timestamp: at(10)},
+		HttpResponse{Source: "a.com", Target: SystemUnderTestDefaultName, Value: &http.Response{StatusCode: http.StatusOK, Request: fast}, Timestamp: at(20)},
+		HttpResponse{Source: "a.com", Target: SystemUnderTestDefaultName, Value: &http.Response{StatusCode: http.StatusOK, Request: slow}, Timestamp: at(100)},
+	}
+
+	w := newWaterfall(events, nil)
+
+	assert.Equal(t, 2, len(w.Lanes))
+	assert.Equal(t, "sut -> a.com: GET /slow", w.Lanes[0][0].Label)
+	assert.Equal(t, 100*time.Millisecond, w.Lanes[0][0].EndOffset)
+	assert.Equal(t, "sut -> a.com: GET /fast", w.Lanes[1][0].Label)
+	assert.Equal(t, 20*time.Millisecond, w.Lanes[1][0].EndOffset)
+}
+
+func TestNewWaterfall_EmptyIfEventsHaveNoTimestamps(t *testing.T) {
+	w := newWaterfall(aRecorder().Events, nil)
+
+	assert.Equal(t, 0, len(w.Lanes))
+}
+
 func TestNewHttpRequestLogEntry(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/path", strings.NewReader(`{"a": 12345}`))
 
@@ -0,0 +1,296 @@
+package apitest
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Fault is a network failure injected by a mock response using MockResponse.Fault
+type Fault int
+
+const (
+	// FaultConnectionReset fails the request with a connection reset by peer error
+	FaultConnectionReset Fault = iota + 1
+	// FaultConnectionRefused fails the request with a connection refused error.
+	// The MockServer closes the connection without responding as the connection was already accepted
+	FaultConnectionRefused
+	// FaultEOFMidBody sends the status and headers and half of the body, then closes the connection
+	FaultEOFMidBody
+	// FaultTruncatedBody sends a Content-Length header for the full body but only sends half of the body
+	FaultTruncatedBody
+	// FaultMalformedChunkedEncoding sends a chunked response with an invalid chunk
+	FaultMalformedChunkedEncoding
+)
+
+var allFaults = []Fault{
+	FaultConnectionReset,
+	FaultConnectionRefused,
+	FaultEOFMidBody,
+	FaultTruncatedBody,
+	FaultMalformedChunkedEncoding,
+}
+
+func (f Fault) String() string {
+	switch f {
+	case FaultConnectionReset:
+		return "connection reset"
+	case FaultConnectionRefused:
+		return "connection refused"
+	case FaultEOFMidBody:
+		return "EOF mid body"
+	case FaultTruncatedBody:
+		return "truncated body"
+	case FaultMalformedChunkedEncoding:
+		return "malformed chunked encoding"
+	}
+	return fmt.Sprintf("Fault(%d)", int(f))
+}
+
+// randomFault picks a fault from a seeded random source so that test runs are repeatable
+type randomFault struct {
+	mu     sync.Mutex
+	seed   int64
+	rand   *rand.Rand
+	faults []Fault
+}
+
+func newRandomFault(seed int64, faults []Fault) *randomFault {
+	if len(faults) == 0 {
+		faults = allFaults
+	}
+	return &randomFault{seed: seed, rand: rand.New(rand.NewSource(seed)), faults: faults}
+}
+
+// copy returns a random fault with a new random source using the same seed, which restarts the sequence of faults
+func (r *randomFault) copy() *randomFault {
+	if r == nil {
+		return nil
+	}
+	return newRandomFault(r.seed, append([]Fault(nil), r.faults...))
+}
+
+func (r *randomFault) next() Fault {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.faults[r.rand.Intn(len(r.faults))]
+}
+
+// dripFeed sends the response body in chunks of the given size with an interval between the chunks
+type dripFeed struct {
+	chunkSize int
+	interval  time.Duration
+}
+
+// Fault injects the given network failure when the mock is matched
+func (r *MockResponse) Fault(fault Fault) *MockResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.fault = fault
+	return r
+}
+
+// RandomFault injects a fault picked at random from the given faults, or from all faults if none are given, each time
+// the mock is matched. The random source is seeded so that the sequence of faults is repeatable
+func (r *MockResponse) RandomFault(seed int64, faults ...Fault) *MockResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.randomFault = newRandomFault(seed, faults)
+	return r
+}
+
+// DripFeed sends the response body slowly in chunks of the given size, waiting for the interval before each chunk
+func (r *MockResponse) DripFeed(chunkSize int, interval time.Duration) *MockResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if chunkSize < 1 {
+		chunkSize = 1
+	}
+	r.dripFeed = &dripFeed{chunkSize: chunkSize, interval: interval}
+	return r
+}
+
+// nextFault returns the fault to inject for the current call, or zero if no fault is defined
+func (r *MockResponse) nextFault() Fault {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.randomFault != nil {
+		return r.randomFault.next()
+	}
+	return r.fault
+}
+
+type mockAddr string
+
+func (a mockAddr) Network() string { return "tcp" }
+func (a mockAddr) String() string  { return string(a) }
+
+// roundTripError returns the error of the faults that prevent a response from being received
+func (f Fault) roundTripError(req *http.Request) error {
+	switch f {
+	case FaultConnectionReset:
+		return &net.OpError{Op: "read", Net: "tcp", Addr: mockAddr(req.URL.Host), Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	case FaultConnectionRefused:
+		return &net.OpError{Op: "dial", Net: "tcp", Addr: mockAddr(req.URL.Host), Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}
+	}
+	return nil
+}
+
+var errMalformedChunkedEncoding = errors.New("malformed chunked encoding")
+
+// applyToResponse replaces the body of the response with a body that fails while it is read
+func (f Fault) applyToResponse(res *http.Response) {
+	switch f {
+	case FaultEOFMidBody, FaultTruncatedBody, FaultMalformedChunkedEncoding:
+	default:
+		return
+	}
+
+	data, _ := ioutil.ReadAll(res.Body)
+	_ = res.Body.Close()
+	res.Header = cloneHeader(res.Header)
+	half := string(data[:len(data)/2])
+	switch f {
+	case FaultEOFMidBody:
+		res.ContentLength = -1
+		res.Header.Del("Content-Length")
+		res.Body = &faultyBody{Reader: strings.NewReader(half), err: io.ErrUnexpectedEOF}
+	case FaultTruncatedBody:
+		res.ContentLength = int64(len(data))
+		res.Header.Set("Content-Length", fmt.Sprintf("%d", len(data)))
+		res.Body = &faultyBody{Reader: strings.NewReader(half), err: io.ErrUnexpectedEOF}
+	case FaultMalformedChunkedEncoding:
+		res.ContentLength = -1
+		res.Header.Del("Content-Length")
+		res.TransferEncoding = []string{"chunked"}
+		res.Body = &faultyBody{Reader: strings.NewReader(""), err: errMalformedChunkedEncoding}
+	}
+}
+
+// faultyBody returns the error once the reader is exhausted
+type faultyBody struct {
+	io.Reader
+	err error
+}
+
+func (b *faultyBody) Read(p []byte) (int, error) {
+	n, err := b.Reader.Read(p)
+	if err == io.EOF {
+		return n, b.err
+	}
+	return n, err
+}
+
+func (b *faultyBody) Close() error {
+	return nil
+}
+
+// dripFeedBody returns at most chunkSize bytes per read, waiting for the interval before each read
+type dripFeedBody struct {
+	ctx  context.Context
+	body io.ReadCloser
+	feed dripFeed
+}
+
+// applyToResponse replaces the body of the response with a body that is read slowly
+func (d *dripFeed) applyToResponse(res *http.Response, ctx context.Context) {
+	if d == nil {
+		return
+	}
+	res.Body = &dripFeedBody{ctx: ctx, body: res.Body, feed: *d}
+}
+
+func (b *dripFeedBody) Read(p []byte) (int, error) {
+	select {
+	case <-time.After(b.feed.interval):
+	case <-b.ctx.Done():
+		return 0, b.ctx.Err()
+	}
+	if len(p) > b.feed.chunkSize {
+		p = p[:b.feed.chunkSize]
+	}
+	return b.body.Read(p)
+}
+
+func (b *dripFeedBody) Close() error {
+	return b.body.Close()
+}
+
+// serveFault writes the fault to the connection of the mock server. Returns false if the fault is not a
+// connection level fault
+func (f Fault) serveFault(w http.ResponseWriter, res *http.Response, body string) bool {
+	switch f {
+	case FaultConnectionReset, FaultConnectionRefused, FaultEOFMidBody, FaultTruncatedBody, FaultMalformedChunkedEncoding:
+	default:
+		return false
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		panic("mock server response writer does not support hijacking")
+	}
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		panic(err)
+	}
+	defer conn.Close()
+
+	switch f {
+	case FaultConnectionReset:
+		if tcpConn, ok := conn.(*net.TCPConn); ok {
+			_ = tcpConn.SetLinger(0)
+		}
+		return true
+	case FaultConnectionRefused:
+		return true
+	}
+
+	half := body[:len(body)/2]
+	res.Header = cloneHeader(res.Header)
+	res.Header.Del("Content-Length")
+	switch f {
+	case FaultEOFMidBody:
+		res.Header.Set("Transfer-Encoding", "chunked")
+		writeStatusAndHeaders(buf, res)
+		_, _ = fmt.Fprintf(buf, "%x\r\n%s\r\n", len(half), half)
+	case FaultTruncatedBody:
+		res.Header.Set("Content-Length", fmt.Sprintf("%d", len(body)))
+		writeStatusAndHeaders(buf, res)
+		_, _ = buf.WriteString(half)
+	case FaultMalformedChunkedEncoding:
+		res.Header.Set("Transfer-Encoding", "chunked")
+		writeStatusAndHeaders(buf, res)
+		_, _ = buf.WriteString("zz\r\nmalformed\r\n")
+	}
+	_ = buf.Flush()
+	return true
+}
+
+func writeStatusAndHeaders(buf *bufio.ReadWriter, res *http.Response) {
+	_, _ = fmt.Fprintf(buf, "HTTP/1.1 %d %s\r\n", res.StatusCode, http.StatusText(res.StatusCode))
+	_ = res.Header.Write(buf)
+	_, _ = buf.WriteString("\r\n")
+}
+
+// cloneHeader copies the header so that the headers of the mock response are not modified
+func cloneHeader(header http.Header) http.Header {
+	if header == nil {
+		return http.Header{}
+	}
+	return header.Clone()
+}
@@ -0,0 +1,116 @@
+package apitest
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestFaults_Transport(t *testing.T) {
+	tests := map[Fault]struct {
+		requestErr error
+		body       string
+		bodyErr    error
+	}{
+		FaultConnectionReset:          {requestErr: syscall.ECONNRESET},
+		FaultConnectionRefused:        {requestErr: syscall.ECONNREFUSED},
+		FaultEOFMidBody:               {body: `{"name"`, bodyErr: io.ErrUnexpectedEOF},
+		FaultTruncatedBody:            {body: `{"name"`, bodyErr: io.ErrUnexpectedEOF},
+		FaultMalformedChunkedEncoding: {bodyErr: errMalformedChunkedEncoding},
+	}
+	for fault, test := range tests {
+		t.Run(fault.String(), func(t *testing.T) {
+			getUser := NewMock().Get("http://localhost:8080/user").RespondWith().Body(`{"name": "jon"}`).Fault(fault).End()
+			reset := NewStandaloneMocks(getUser).End()
+			defer reset()
+
+			res, err := http.Get("http://localhost:8080/user")
+
+			if test.requestErr != nil {
+				assert.True(t, errors.Is(err, test.requestErr))
+				return
+			}
+			assert.NoError(t, err)
+			body, err := ioutil.ReadAll(res.Body)
+			assert.Equal(t, test.body, string(body))
+			assert.True(t, errors.Is(err, test.bodyErr))
+		})
+	}
+}
+
+func TestFaults_TruncatedBodySetsFullContentLength(t *testing.T) {
+	getUser := NewMock().Get("http://localhost:8080/user").RespondWith().Body("abcdef").Fault(FaultTruncatedBody).End()
+	reset := NewStandaloneMocks(getUser).End()
+	defer reset()
+
+	res, err := http.Get("http://localhost:8080/user")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(6), res.ContentLength)
+	assert.Equal(t, 0, len(getUser.response.headers["Content-Length"]))
+}
+
+func TestFaults_RandomFaultIsRepeatable(t *testing.T) {
+	sequence := func() []Fault {
+		response := NewMock().Get("/").RespondWith().RandomFault(42)
+		var faults []Fault
+		for i := 0; i < 10; i++ {
+			faults = append(faults, response.nextFault())
+		}
+		return faults
+	}
+
+	assert.Equal(t, sequence(), sequence())
+	response := NewMock().Get("/").RespondWith().RandomFault(7, FaultConnectionReset)
+	assert.Equal(t, FaultConnectionReset, response.nextFault())
+}
+
+func TestFaults_DripFeed(t *testing.T) {
+	getUser := NewMock().Get("http://localhost:8080/user").RespondWith().Body("abcd").DripFeed(2, 20*time.Millisecond).End()
+	reset := NewStandaloneMocks(getUser).End()
+	defer reset()
+	started := time.Now()
+
+	res, err := http.Get("http://localhost:8080/user")
+	assert.NoError(t, err)
+	body, err := ioutil.ReadAll(res.Body)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "abcd", string(body))
+	assert.True(t, time.Since(started) >= 40*time.Millisecond)
+}
+
+func TestFaults_MockServer(t *testing.T) {
+	for _, fault := range []Fault{FaultConnectionReset, FaultConnectionRefused, FaultEOFMidBody, FaultTruncatedBody, FaultMalformedChunkedEncoding} {
+		t.Run(fault.String(), func(t *testing.T) {
+			server := NewMockServer(NewMock().Get("/user").RespondWith().Body(`{"name": "jon"}`).Fault(fault).End())
+			defer server.Close()
+			cli := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+
+			res, err := cli.Get(server.URL + "/user")
+			if err == nil {
+				_, err = ioutil.ReadAll(res.Body)
+			}
+
+			assert.True(t, err != nil)
+		})
+	}
+}
+
+func TestFaults_MockServerDripFeed(t *testing.T) {
+	server := NewMockServer(NewMock().Get("/user").RespondWith().Body("abcd").DripFeed(1, 10*time.Millisecond).End())
+	defer server.Close()
+	started := time.Now()
+
+	res, err := http.Get(server.URL + "/user")
+	assert.NoError(t, err)
+	body, err := ioutil.ReadAll(res.Body)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "abcd", string(body))
+	assert.True(t, time.Since(started) >= 40*time.Millisecond)
+}
@@ -2,4 +2,7 @@ module github.com/steinfletcher/apitest
 
 go 1.23
 
-require github.com/davecgh/go-spew v1.1.1
+require (
+	github.com/davecgh/go-spew v1.1.1
+	gopkg.in/yaml.v3 v3.0.1
+)
@@ -0,0 +1,493 @@
+package apitest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// GraphQLResponseBody represents the response body as per the GraphQL spec
+type GraphQLResponseBody struct {
+	Data       interface{}            `json:"data,omitempty"`
+	Errors     []GraphQLError         `json:"errors,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// GraphQLError represents an error in a GraphQL response
+type GraphQLError struct {
+	Message    string                 `json:"message"`
+	Locations  []GraphQLErrorLocation `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// GraphQLErrorLocation represents the location in the query document of a GraphQL error
+type GraphQLErrorLocation struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// GraphQL configures the mock to match GraphQL POST requests to the given url. Use the GraphQL methods of
+// MockRequest to match the operation name, query and variables
+func (m *Mock) GraphQL(u string) *MockRequest {
+	return m.Post(u).AddMatcher(func(req *http.Request, _ *MockRequest) error {
+		_, err := readGraphQLRequest(req)
+		return err
+	})
+}
+
+// GraphQLOperation configures the mock request to match the GraphQL operation name. If the request has no
+// operationName the name of the single operation in the query document is used
+func (r *MockRequest) GraphQLOperation(name string) *MockRequest {
+	return r.AddMatcher(func(req *http.Request, _ *MockRequest) error {
+		body, err := readGraphQLRequest(req)
+		if err != nil {
+			return err
+		}
+		operationName := body.OperationName
+		if operationName == "" {
+			operationName = graphQLOperationName(body.Query)
+		}
+		if operationName != name {
+			return fmt.Errorf("received GraphQL operation %s did not match the expected operation %s", operationName, name)
+		}
+		return nil
+	})
+}
+
+// GraphQLQuery configures the mock request to match the GraphQL query document. Differences in whitespace,
+// commas, comments and the order of fields within a selection set are ignored
+func (r *MockRequest) GraphQLQuery(query string) *MockRequest {
+	expected, err := normalizeGraphQL(query)
+	if err != nil {
+		panic(fmt.Sprintf("invalid GraphQL query: %s", err))
+	}
+
+	return r.AddMatcher(func(req *http.Request, _ *MockRequest) error {
+		body, err := readGraphQLRequest(req)
+		if err != nil {
+			return err
+		}
+		actual, err := normalizeGraphQL(body.Query)
+		if err != nil {
+			return fmt.Errorf("received GraphQL query is invalid: %s", err)
+		}
+		if actual != expected {
+			return fmt.Errorf("received GraphQL query %s did not match the expected query %s", actual, expected)
+		}
+		return nil
+	})
+}
+
+// GraphQLVariables configures the mock request to match GraphQL variables containing the given variables.
+// Variables of the request that are not given are ignored
+func (r *MockRequest) GraphQLVariables(variables map[string]interface{}) *MockRequest {
+	expected, err := toJSONValue(variables)
+	if err != nil {
+		panic(err)
+	}
+
+	return r.AddMatcher(func(req *http.Request, _ *MockRequest) error {
+		body, err := readGraphQLRequest(req)
+		if err != nil {
+			return err
+		}
+		actual, err := toJSONValue(body.Variables)
+		if err != nil {
+			return err
+		}
+		if actual == nil {
+			actual = map[string]interface{}{}
+		}
+		if err := jsonSubset("$", expected, actual, false); err != nil {
+			return fmt.Errorf("received GraphQL variables did not match: %s", err)
+		}
+		return nil
+	})
+}
+
+// GraphQLRequest configures the mock request to match the non empty fields of the GraphQL request body
+func (r *MockRequest) GraphQLRequest(body GraphQLRequestBody) *MockRequest {
+	if body.OperationName != "" {
+		r.GraphQLOperation(body.OperationName)
+	}
+	if body.Query != "" {
+		r.GraphQLQuery(body.Query)
+	}
+	if body.Variables != nil {
+		r.GraphQLVariables(body.Variables)
+	}
+	return r
+}
+
+// GraphQLData sets the data of the GraphQL mock response body
+func (r *MockResponse) GraphQLData(data interface{}) *MockResponse {
+	return r.graphQLResponse(func(body *GraphQLResponseBody) {
+		body.Data = data
+	})
+}
+
+// GraphQLErrors adds errors to the GraphQL mock response body
+func (r *MockResponse) GraphQLErrors(errs ...GraphQLError) *MockResponse {
+	return r.graphQLResponse(func(body *GraphQLResponseBody) {
+		body.Errors = append(body.Errors, errs...)
+	})
+}
+
+// GraphQLExtensions sets the extensions of the GraphQL mock response body
+func (r *MockResponse) GraphQLExtensions(extensions map[string]interface{}) *MockResponse {
+	return r.graphQLResponse(func(body *GraphQLResponseBody) {
+		body.Extensions = extensions
+	})
+}
+
+// GraphQLResponse sets the GraphQL mock response body
+func (r *MockResponse) GraphQLResponse(body GraphQLResponseBody) *MockResponse {
+	return r.graphQLResponse(func(b *GraphQLResponseBody) {
+		*b = body
+	})
+}
+
+func (r *MockResponse) graphQLResponse(update func(*GraphQLResponseBody)) *MockResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.graphQL == nil {
+		r.graphQL = &GraphQLResponseBody{}
+	}
+	update(r.graphQL)
+
+	data, err := json.Marshal(r.graphQL)
+	if err != nil {
+		panic(err)
+	}
+	r.body = string(data)
+	r.headers["Content-Type"] = []string{"application/json"}
+	return r
+}
+
+// readGraphQLRequest decodes the GraphQL request body, replacing the body so that it can be read again
+func readGraphQLRequest(req *http.Request) (GraphQLRequestBody, error) {
+	var body GraphQLRequestBody
+	if req.Body == nil {
+		return body, errors.New("expected a GraphQL request body but received none")
+	}
+	data, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return body, err
+	}
+	req.Body = ioutil.NopCloser(strings.NewReader(string(data)))
+
+	if err := json.Unmarshal(data, &body); err != nil {
+		return body, fmt.Errorf("received body is not a valid GraphQL request: %s", err)
+	}
+	if body.Query == "" {
+		return body, errors.New("received body is not a valid GraphQL request: missing query")
+	}
+	return body, nil
+}
+
+// graphQLOperationName returns the name of the operation in a query document containing a single operation
+func graphQLOperationName(query string) string {
+	tokens, err := tokenizeGraphQL(query)
+	if err != nil {
+		return ""
+	}
+	definitions, err := (&graphQLParser{tokens: tokens}).document()
+	if err != nil {
+		return ""
+	}
+
+	name := ""
+	operations := 0
+	for _, definition := range definitions {
+		switch definition.tokens[0] {
+		case "query", "mutation", "subscription":
+			operations++
+			if len(definition.tokens) > 1 && isGraphQLName(definition.tokens[1]) {
+				name = definition.tokens[1]
+			}
+		case "fragment":
+		default:
+			operations++
+		}
+	}
+	if operations != 1 {
+		return ""
+	}
+	return name
+}
+
+// normalizeGraphQL returns a canonical form of the query document which ignores insignificant whitespace, commas
+// and comments, and sorts the definitions of the document and the selections of each selection set
+func normalizeGraphQL(query string) (string, error) {
+	tokens, err := tokenizeGraphQL(query)
+	if err != nil {
+		return "", err
+	}
+	definitions, err := (&graphQLParser{tokens: tokens}).document()
+	if err != nil {
+		return "", err
+	}
+
+	normalized := make([]string, len(definitions))
+	for i, definition := range definitions {
+		normalized[i] = definition.String()
+	}
+	sort.Strings(normalized)
+	return strings.Join(normalized, " "), nil
+}
+
+// graphQLNode is a run of tokens optionally followed by a selection set
+type graphQLNode struct {
+	tokens     []string
+	selections []graphQLNode
+}
+
+func (n graphQLNode) String() string {
+	parts := append([]string{}, n.tokens...)
+	if n.selections != nil {
+		selections := make([]string, len(n.selections))
+		for i, selection := range n.selections {
+			selections[i] = selection.String()
+		}
+		sort.Strings(selections)
+		parts = append(parts, "{")
+		parts = append(parts, selections...)
+		parts = append(parts, "}")
+	}
+	return strings.Join(parts, " ")
+}
+
+type graphQLParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *graphQLParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *graphQLParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *graphQLParser) document() ([]graphQLNode, error) {
+	var definitions []graphQLNode
+	for p.pos < len(p.tokens) {
+		var definition graphQLNode
+		for p.peek() != "{" {
+			if p.pos >= len(p.tokens) {
+				return nil, errors.New("expected {")
+			}
+			tokens, err := p.balanced()
+			if err != nil {
+				return nil, err
+			}
+			definition.tokens = append(definition.tokens, tokens...)
+		}
+		selections, err := p.selectionSet()
+		if err != nil {
+			return nil, err
+		}
+		definition.selections = selections
+		if definition.tokens == nil {
+			definition.tokens = []string{"query"}
+		}
+		definitions = append(definitions, definition)
+	}
+	if len(definitions) == 0 {
+		return nil, errors.New("empty document")
+	}
+	return definitions, nil
+}
+
+func (p *graphQLParser) selectionSet() ([]graphQLNode, error) {
+	p.next() // {
+	selections := []graphQLNode{}
+	for p.peek() != "}" {
+		if p.pos >= len(p.tokens) {
+			return nil, errors.New("expected }")
+		}
+		selection, err := p.selection()
+		if err != nil {
+			return nil, err
+		}
+		selections = append(selections, selection)
+	}
+	p.next() // }
+	if len(selections) == 0 {
+		return nil, errors.New("empty selection set")
+	}
+	return selections, nil
+}
+
+// selection parses a field, fragment spread or inline fragment
+func (p *graphQLParser) selection() (graphQLNode, error) {
+	var selection graphQLNode
+	switch token := p.next(); {
+	case token == "...":
+		selection.tokens = append(selection.tokens, token)
+		if p.peek() == "on" {
+			selection.tokens = append(selection.tokens, p.next(), p.next())
+		} else if isGraphQLName(p.peek()) {
+			selection.tokens = append(selection.tokens, p.next())
+		}
+	case isGraphQLName(token):
+		selection.tokens = append(selection.tokens, token)
+		if p.peek() == ":" {
+			selection.tokens = append(selection.tokens, p.next(), p.next())
+		}
+		if p.peek() == "(" {
+			tokens, err := p.balanced()
+			if err != nil {
+				return selection, err
+			}
+			selection.tokens = append(selection.tokens, tokens...)
+		}
+	default:
+		return selection, fmt.Errorf("unexpected %s", token)
+	}
+
+	for p.peek() == "@" {
+		selection.tokens = append(selection.tokens, p.next(), p.next())
+		if p.peek() == "(" {
+			tokens, err := p.balanced()
+			if err != nil {
+				return selection, err
+			}
+			selection.tokens = append(selection.tokens, tokens...)
+		}
+	}
+
+	if p.peek() == "{" {
+		selections, err := p.selectionSet()
+		if err != nil {
+			return selection, err
+		}
+		selection.selections = selections
+	}
+	return selection, nil
+}
+
+// balanced returns the next token, or if it opens a parenthesis or bracket, all tokens up to the matching close
+func (p *graphQLParser) balanced() ([]string, error) {
+	var tokens []string
+	depth := 0
+	for {
+		if p.pos >= len(p.tokens) {
+			return nil, errors.New("unbalanced brackets")
+		}
+		token := p.next()
+		tokens = append(tokens, token)
+		switch token {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+		}
+		if depth <= 0 {
+			return tokens, nil
+		}
+	}
+}
+
+func isGraphQLName(token string) bool {
+	if token == "" {
+		return false
+	}
+	for i, c := range token {
+		if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9' {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// tokenizeGraphQL splits a query document into its lexical tokens, dropping whitespace, commas and comments
+func tokenizeGraphQL(query string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == ',':
+			i++
+		case strings.HasPrefix(query[i:], "\uFEFF"):
+			i += len("\uFEFF")
+		case c == '#':
+			for i < len(query) && query[i] != '\n' && query[i] != '\r' {
+				i++
+			}
+		case strings.HasPrefix(query[i:], "..."):
+			tokens = append(tokens, "...")
+			i += 3
+		case strings.ContainsRune("!$&():=@[]{|}", rune(c)):
+			tokens = append(tokens, string(c))
+			i++
+		case strings.HasPrefix(query[i:], `"""`):
+			end := strings.Index(query[i+3:], `"""`)
+			for end >= 0 && strings.HasSuffix(query[i+3:i+3+end], `\`) {
+				next := strings.Index(query[i+3+end+1:], `"""`)
+				if next < 0 {
+					end = -1
+					break
+				}
+				end += next + 1
+			}
+			if end < 0 {
+				return nil, errors.New("unterminated block string")
+			}
+			tokens = append(tokens, query[i:i+3+end+3])
+			i += 3 + end + 3
+		case c == '"':
+			end := i + 1
+			for end < len(query) && query[end] != '"' {
+				if query[end] == '\\' {
+					end++
+				}
+				if end < len(query) && (query[end] == '\n' || query[end] == '\r') {
+					return nil, errors.New("unterminated string")
+				}
+				end++
+			}
+			if end >= len(query) {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, query[i:end+1])
+			i = end + 1
+		case c == '-' || c >= '0' && c <= '9':
+			end := i + 1
+			for end < len(query) && (isGraphQLNameChar(query[end]) || strings.IndexByte(".+-", query[end]) >= 0) {
+				end++
+			}
+			tokens = append(tokens, query[i:end])
+			i = end
+		case isGraphQLNameChar(c):
+			end := i + 1
+			for end < len(query) && isGraphQLNameChar(query[end]) {
+				end++
+			}
+			tokens = append(tokens, query[i:end])
+			i = end
+		default:
+			r, _ := utf8.DecodeRuneInString(query[i:])
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+	return tokens, nil
+}
+
+func isGraphQLNameChar(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
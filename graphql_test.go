@@ -0,0 +1,187 @@
+package apitest
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGraphQL_NormalizesQuery(t *testing.T) {
+	tests := map[string]struct {
+		a, b  string
+		equal bool
+	}{
+		"whitespace and commas": {
+			a:     `query GetUser($id: ID!) { user(id: $id) { id, name } }`,
+			b:     "query GetUser($id: ID!) {\n  user(id: $id) {\n    id\n    name\n  }\n}",
+			equal: true,
+		},
+		"field order": {
+			a:     `{ user { name id posts { title } } }`,
+			b:     `query { user { posts { title } id name } }`,
+			equal: true,
+		},
+		"comments": {
+			a:     "{ user { id # the id\n name } }",
+			b:     `{ user { id name } }`,
+			equal: true,
+		},
+		"fragments": {
+			a:     `query { user { ...UserFields ... on Admin { role } } } fragment UserFields on User { id name }`,
+			b:     `fragment UserFields on User { name id } query { user { ... on Admin { role } ...UserFields } }`,
+			equal: true,
+		},
+		"strings are preserved": {
+			a:     `{ user(name: "jon  smith") { id } }`,
+			b:     `{ user(name: "jon smith") { id } }`,
+			equal: false,
+		},
+		"different fields": {
+			a:     `{ user { id name } }`,
+			b:     `{ user { id email } }`,
+			equal: false,
+		},
+		"different arguments": {
+			a:     `{ user(id: 1) { id } }`,
+			b:     `{ user(id: 2) { id } }`,
+			equal: false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			a, err := normalizeGraphQL(test.a)
+			assert.NoError(t, err)
+			b, err := normalizeGraphQL(test.b)
+			assert.NoError(t, err)
+
+			assert.Equal(t, test.equal, a == b)
+		})
+	}
+}
+
+func TestGraphQL_InvalidQuery(t *testing.T) {
+	for _, query := range []string{``, `{ user { id }`, `{ user { } }`, `{ user(name: "jon) { id } }`, `query ~ { id }`} {
+		t.Run(query, func(t *testing.T) {
+			_, err := normalizeGraphQL(query)
+
+			assert.True(t, err != nil)
+		})
+	}
+}
+
+func TestGraphQL_OperationName(t *testing.T) {
+	assert.Equal(t, "GetUser", graphQLOperationName(`query GetUser { user { id } }`))
+	assert.Equal(t, "GetUser", graphQLOperationName(`query GetUser { user { ...F } } fragment F on User { id }`))
+	assert.Equal(t, "", graphQLOperationName(`{ user { id } }`))
+	assert.Equal(t, "", graphQLOperationName(`query A { a } query B { b }`))
+}
+
+func TestGraphQL_Matches(t *testing.T) {
+	tests := map[string]struct {
+		mock *Mock
+		body string
+		err  string
+	}{
+		"query": {
+			mock: NewMock().GraphQL("/graphql").GraphQLQuery(`query GetUser($id: ID!) { user(id: $id) { name id } }`).RespondWith().End(),
+			body: `{"query": "query GetUser($id: ID!) {\n user(id: $id) { id name }\n}", "variables": {"id": "1"}}`,
+		},
+		"query mismatch": {
+			mock: NewMock().GraphQL("/graphql").GraphQLQuery(`{ user { id } }`).RespondWith().End(),
+			body: `{"query": "{ user { name } }"}`,
+			err:  "received GraphQL query query { user { name } } did not match the expected query query { user { id } }",
+		},
+		"operation name": {
+			mock: NewMock().GraphQL("/graphql").GraphQLOperation("GetUser").RespondWith().End(),
+			body: `{"query": "query GetUser { user { id } }"}`,
+		},
+		"operation name from body": {
+			mock: NewMock().GraphQL("/graphql").GraphQLOperation("GetUser").RespondWith().End(),
+			body: `{"query": "query GetUser { user { id } } query GetPosts { posts { id } }", "operationName": "GetUser"}`,
+		},
+		"operation name mismatch": {
+			mock: NewMock().GraphQL("/graphql").GraphQLOperation("GetUser").RespondWith().End(),
+			body: `{"query": "query GetPosts { posts { id } }"}`,
+			err:  "received GraphQL operation GetPosts did not match the expected operation GetUser",
+		},
+		"variables subset": {
+			mock: NewMock().GraphQL("/graphql").GraphQLVariables(map[string]interface{}{"id": "1"}).RespondWith().End(),
+			body: `{"query": "{ user { id } }", "variables": {"id": "1", "locale": "en"}}`,
+		},
+		"variables mismatch": {
+			mock: NewMock().GraphQL("/graphql").GraphQLVariables(map[string]interface{}{"id": "1"}).RespondWith().End(),
+			body: `{"query": "{ user { id } }", "variables": {"id": "2"}}`,
+			err:  `received GraphQL variables did not match: at $.id expected "1" but received "2"`,
+		},
+		"missing variables": {
+			mock: NewMock().GraphQL("/graphql").GraphQLVariables(map[string]interface{}{"id": "1"}).RespondWith().End(),
+			body: `{"query": "{ user { id } }"}`,
+			err:  `received GraphQL variables did not match: at $ expected field 'id' but it was not present`,
+		},
+		"request body": {
+			mock: NewMock().GraphQL("/graphql").GraphQLRequest(GraphQLRequestBody{
+				Query:         `query GetUser { user { id } }`,
+				OperationName: "GetUser",
+			}).RespondWith().End(),
+			body: `{"query": "query GetUser { user { id } }", "variables": {"id": "1"}}`,
+		},
+		"not graphql": {
+			mock: NewMock().GraphQL("/graphql").RespondWith().End(),
+			body: `{"name": "jon"}`,
+			err:  "received body is not a valid GraphQL request: missing query",
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(test.body))
+
+			errs := test.mock.Matches(req)
+
+			if test.err == "" {
+				assert.Equal(t, 0, len(errs))
+				return
+			}
+			assert.Equal(t, 1, len(errs))
+			assert.Equal(t, test.err, errs[0].Error())
+		})
+	}
+}
+
+func TestGraphQL_Response(t *testing.T) {
+	getUser := NewMock().
+		GraphQL("http://localhost:8080/graphql").
+		GraphQLOperation("GetUser").
+		RespondWith().
+		GraphQLData(map[string]interface{}{"user": nil}).
+		GraphQLErrors(GraphQLError{
+			Message:   "user not found",
+			Locations: []GraphQLErrorLocation{{Line: 1, Column: 17}},
+			Path:      []interface{}{"user"},
+		}).
+		GraphQLExtensions(map[string]interface{}{"requestId": "abc"}).
+		End()
+	reset := NewStandaloneMocks(getUser).End()
+	defer reset()
+
+	res, err := http.Post("http://localhost:8080/graphql", "application/json",
+		strings.NewReader(`{"query": "query GetUser { user { id } }"}`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	body, err := ioutil.ReadAll(res.Body)
+	assert.NoError(t, err)
+	var actual map[string]interface{}
+	assert.NoError(t, json.Unmarshal(body, &actual))
+	assert.Equal(t, map[string]interface{}{
+		"data": map[string]interface{}{"user": nil},
+		"errors": []interface{}{map[string]interface{}{
+			"message":   "user not found",
+			"locations": []interface{}{map[string]interface{}{"line": float64(1), "column": float64(17)}},
+			"path":      []interface{}{"user"},
+		}},
+		"extensions": map[string]interface{}{"requestId": "abc"},
+	}, actual)
+}
@@ -0,0 +1,299 @@
+package apitest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// JSONSubsetOption configures the comparison made by MockRequest.JSONSubset
+type JSONSubsetOption int
+
+const (
+	// IgnoreArrayOrder matches arrays containing the expected elements in any order
+	IgnoreArrayOrder JSONSubsetOption = iota + 1
+)
+
+// JSONValueMatcher checks the value selected by MockRequest.JSONPath, returning an error describing the mismatch
+type JSONValueMatcher func(value interface{}) error
+
+// JSONValueEquals matches values equal to the expected value once both are converted to JSON,
+// e.g. JSONValueEquals(12) matches the JSON number 12 and JSONValueEquals("12") matches the JSON string "12"
+func JSONValueEquals(expected interface{}) JSONValueMatcher {
+	return func(value interface{}) error {
+		data, err := json.Marshal(expected)
+		if err != nil {
+			return err
+		}
+		var expectedValue interface{}
+		if err := json.Unmarshal(data, &expectedValue); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(expectedValue, value) {
+			return fmt.Errorf("expected %s but received %s", jsonString(expectedValue), jsonString(value))
+		}
+		return nil
+	}
+}
+
+// JSONValueMatches matches string values using the given regular expression
+func JSONValueMatches(expression string) JSONValueMatcher {
+	return func(value interface{}) error {
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("expected a string matching %s but received %s", expression, jsonString(value))
+		}
+		matched, err := regexp.MatchString(expression, s)
+		if err != nil {
+			return err
+		}
+		if !matched {
+			return fmt.Errorf("expected a string matching %s but received %s", expression, jsonString(value))
+		}
+		return nil
+	}
+}
+
+// JSONValuePresent matches any value, including null
+func JSONValuePresent() JSONValueMatcher {
+	return func(value interface{}) error {
+		return nil
+	}
+}
+
+func toJSONValue(v interface{}) (interface{}, error) {
+	var data []byte
+	switch x := v.(type) {
+	case string:
+		data = []byte(x)
+		if !json.Valid(data) {
+			return x, nil
+		}
+	case []byte:
+		data = x
+	default:
+		var err error
+		if data, err = json.Marshal(x); err != nil {
+			return nil, err
+		}
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func jsonString(v interface{}) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(data)
+}
+
+// jsonSubset returns an error describing the first difference if expected is not a subset of actual
+func jsonSubset(path string, expected, actual interface{}, ignoreArrayOrder bool) error {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("at %s expected an object but received %s", path, jsonString(actual))
+		}
+		keys := make([]string, 0, len(e))
+		for key := range e {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			value, ok := a[key]
+			if !ok {
+				return fmt.Errorf("at %s expected field '%s' but it was not present", path, key)
+			}
+			if err := jsonSubset(path+"."+key, e[key], value, ignoreArrayOrder); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok {
+			return fmt.Errorf("at %s expected an array but received %s", path, jsonString(actual))
+		}
+		if len(e) != len(a) {
+			return fmt.Errorf("at %s expected an array of length %d but received length %d", path, len(e), len(a))
+		}
+		if !ignoreArrayOrder {
+			for i := range e {
+				if err := jsonSubset(fmt.Sprintf("%s[%d]", path, i), e[i], a[i], ignoreArrayOrder); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		return jsonSubsetUnordered(path, e, a)
+	default:
+		if !reflect.DeepEqual(expected, actual) {
+			return fmt.Errorf("at %s expected %s but received %s", path, jsonString(expected), jsonString(actual))
+		}
+		return nil
+	}
+}
+
+// jsonSubsetUnordered checks that each expected element matches a different actual element in any order. An
+// expected element can match several actual elements, so the elements are paired using bipartite matching instead of
+// pairing each expected element with the first actual element it matches
+func jsonSubsetUnordered(path string, expected, actual []interface{}) error {
+	candidates := make([][]int, len(expected))
+	for i := range expected {
+		for j := range actual {
+			if jsonSubset(path, expected[i], actual[j], true) == nil {
+				candidates[i] = append(candidates[i], j)
+			}
+		}
+	}
+
+	pairedWith := make([]int, len(actual))
+	for j := range pairedWith {
+		pairedWith[j] = -1
+	}
+	var pair func(i int, visited []bool) bool
+	pair = func(i int, visited []bool) bool {
+		for _, j := range candidates[i] {
+			if visited[j] {
+				continue
+			}
+			visited[j] = true
+			if pairedWith[j] < 0 || pair(pairedWith[j], visited) {
+				pairedWith[j] = i
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := range expected {
+		if !pair(i, make([]bool, len(actual))) {
+			return fmt.Errorf("at %s expected an element matching %s but none was found", path, jsonString(expected[i]))
+		}
+	}
+	return nil
+}
+
+// jsonPath evaluates a JSONPath expression against a decoded JSON document. Supports child fields using dot or
+// bracket notation, array indexes and the * wildcard, e.g. $.items[*].id or $['user'].tags[0].
+// The matched values are returned as an array if the expression contains a wildcard
+func jsonPath(document interface{}, expression string) (interface{}, error) {
+	if !strings.HasPrefix(expression, "$") {
+		return nil, fmt.Errorf("invalid JSONPath expression %s: must start with $", expression)
+	}
+
+	values := []interface{}{document}
+	wildcard := false
+	rest := expression[1:]
+	for rest != "" {
+		var selector string
+		switch {
+		case strings.HasPrefix(rest, "["):
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("invalid JSONPath expression %s: missing ]", expression)
+			}
+			selector, rest = rest[1:end], rest[end+1:]
+		case strings.HasPrefix(rest, "."):
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			selector, rest = rest[:end], rest[end:]
+			if selector == "" {
+				return nil, fmt.Errorf("invalid JSONPath expression %s: empty field name", expression)
+			}
+			if selector != "*" {
+				selector = strconv.Quote(selector)
+			}
+		default:
+			return nil, fmt.Errorf("invalid JSONPath expression %s: unexpected %s", expression, rest)
+		}
+
+		if selector == "*" {
+			wildcard = true
+		}
+		var next []interface{}
+		for _, value := range values {
+			selected, err := selectJSON(value, selector)
+			if err != nil {
+				if wildcard {
+					continue
+				}
+				return nil, err
+			}
+			next = append(next, selected...)
+		}
+		values = next
+	}
+
+	if wildcard {
+		if values == nil {
+			values = []interface{}{}
+		}
+		return values, nil
+	}
+	return values[0], nil
+}
+
+func selectJSON(value interface{}, selector string) ([]interface{}, error) {
+	if selector == "*" {
+		switch v := value.(type) {
+		case []interface{}:
+			return v, nil
+		case map[string]interface{}:
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			var values []interface{}
+			for _, key := range keys {
+				values = append(values, v[key])
+			}
+			return values, nil
+		}
+		return nil, errors.New("wildcard used on a value that is not an object or array")
+	}
+
+	if strings.HasPrefix(selector, `"`) || strings.HasPrefix(selector, "'") {
+		key := strings.Trim(selector, `"'`)
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("field '%s' selected on a value that is not an object", key)
+		}
+		field, ok := object[key]
+		if !ok {
+			return nil, fmt.Errorf("field '%s' not found", key)
+		}
+		return []interface{}{field}, nil
+	}
+
+	index, err := strconv.Atoi(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector [%s]", selector)
+	}
+	array, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("index %d selected on a value that is not an array", index)
+	}
+	if index < 0 {
+		index += len(array)
+	}
+	if index < 0 || index >= len(array) {
+		return nil, fmt.Errorf("index %d out of range", index)
+	}
+	return []interface{}{array[index]}, nil
+}
@@ -0,0 +1,124 @@
+package apitest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestJSONSubset_Matches(t *testing.T) {
+	tests := map[string]struct {
+		expected string
+		options  []JSONSubsetOption
+		body     string
+		err      string
+	}{
+		"ignores extra fields": {
+			expected: `{"user": {"name": "jon"}}`,
+			body:     `{"id": "6f1b", "created": "2024-01-01", "user": {"name": "jon", "age": 20}}`,
+		},
+		"different value": {
+			expected: `{"user": {"name": "jon"}}`,
+			body:     `{"user": {"name": "jan"}}`,
+			err:      `received body did not match the JSON subset: at $.user.name expected "jon" but received "jan"`,
+		},
+		"missing field": {
+			expected: `{"user": {"name": "jon"}}`,
+			body:     `{"user": {}}`,
+			err:      `received body did not match the JSON subset: at $.user expected field 'name' but it was not present`,
+		},
+		"array order": {
+			expected: `{"ids": [1, 2]}`,
+			body:     `{"ids": [2, 1]}`,
+			err:      `received body did not match the JSON subset: at $.ids[0] expected 1 but received 2`,
+		},
+		"ignore array order": {
+			expected: `{"items": [{"id": 1}, {"id": 2}]}`,
+			options:  []JSONSubsetOption{IgnoreArrayOrder},
+			body:     `{"items": [{"id": 2, "x": true}, {"id": 1}]}`,
+		},
+		"ignore array order missing element": {
+			expected: `{"ids": [1, 3]}`,
+			options:  []JSONSubsetOption{IgnoreArrayOrder},
+			body:     `{"ids": [2, 1]}`,
+			err:      `received body did not match the JSON subset: at $.ids expected an element matching 3 but none was found`,
+		},
+		"ignore array order overlapping elements": {
+			expected: `[{"a": 1}, {"a": 1, "b": 2}]`,
+			options:  []JSONSubsetOption{IgnoreArrayOrder},
+			body:     `[{"a": 1, "b": 2}, {"a": 1}]`,
+		},
+		"ignore array order overlapping elements missing element": {
+			expected: `[{"a": 1}, {"a": 1, "b": 2}]`,
+			options:  []JSONSubsetOption{IgnoreArrayOrder},
+			body:     `[{"a": 1, "b": 3}, {"a": 1}]`,
+			err:      `received body did not match the JSON subset: at $ expected an element matching {"a":1,"b":2} but none was found`,
+		},
+		"invalid body": {
+			expected: `{"a": 1}`,
+			body:     `abc`,
+			err:      `received body is not valid JSON: invalid character 'a' looking for beginning of value`,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mock := NewMock().Post("/user").JSONSubset(test.expected, test.options...).RespondWith().End()
+			req := httptest.NewRequest(http.MethodPost, "/user", strings.NewReader(test.body))
+
+			errs := mock.Matches(req)
+
+			if test.err == "" {
+				assert.Equal(t, 0, len(errs))
+				return
+			}
+			assert.Equal(t, 1, len(errs))
+			assert.Equal(t, test.err, errs[0].Error())
+		})
+	}
+}
+
+func TestJSONSubset_AcceptsValues(t *testing.T) {
+	mock := NewMock().Post("/user").JSONSubset(map[string]interface{}{"age": 20}).RespondWith().End()
+	req := httptest.NewRequest(http.MethodPost, "/user", strings.NewReader(`{"name": "jon", "age": 20}`))
+
+	assert.Equal(t, 0, len(mock.Matches(req)))
+}
+
+func TestJSONPath_Matches(t *testing.T) {
+	body := `{"id": "6f1b", "ref": "12", "user": {"name": "jon", "tags": ["a", "b"]}, "items": [{"id": 1}, {"id": 2}]}`
+	tests := map[string]struct {
+		expression string
+		matcher    JSONValueMatcher
+		err        string
+	}{
+		"field":              {"$.user.name", JSONValueEquals("jon"), ""},
+		"bracket notation":   {"$['user'].tags[1]", JSONValueEquals("b"), ""},
+		"negative index":     {"$.user.tags[-1]", JSONValueEquals("b"), ""},
+		"wildcard":           {"$.items[*].id", JSONValueEquals([]int{1, 2}), ""},
+		"numeric string":     {"$.ref", JSONValueEquals("12"), ""},
+		"number not string":  {"$.ref", JSONValueEquals(12), `received body value at $.ref did not match: expected 12 but received "12"`},
+		"regexp":             {"$.id", JSONValueMatches("^[0-9a-f]+$"), ""},
+		"present":            {"$.user", JSONValuePresent(), ""},
+		"different value":    {"$.items[0].id", JSONValueEquals(2), "received body value at $.items[0].id did not match: expected 2 but received 1"},
+		"regexp not string":  {"$.items[0].id", JSONValueMatches("1"), "received body value at $.items[0].id did not match: expected a string matching 1 but received 1"},
+		"missing field":      {"$.user.age", JSONValuePresent(), "received body has no value at $.user.age: field 'age' not found"},
+		"index out of range": {"$.user.tags[2]", JSONValuePresent(), "received body has no value at $.user.tags[2]: index 2 out of range"},
+		"invalid expression": {"user.name", JSONValuePresent(), "received body has no value at user.name: invalid JSONPath expression user.name: must start with $"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			mock := NewMock().Post("/user").JSONPath(test.expression, test.matcher).RespondWith().End()
+			req := httptest.NewRequest(http.MethodPost, "/user", strings.NewReader(body))
+
+			errs := mock.Matches(req)
+
+			if test.err == "" {
+				assert.Equal(t, 0, len(errs))
+				return
+			}
+			assert.Equal(t, 1, len(errs))
+			assert.Equal(t, test.err, errs[0].Error())
+		})
+	}
+}
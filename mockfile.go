@@ -0,0 +1,502 @@
+package apitest
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// mockMappingFile is the top level structure of a mock definition file. A file contains either a single mapping,
+// a list of mappings or an object holding the list of mappings in the "mappings" field as exported by WireMock
+type mockMappingFile struct {
+	Mappings    []mockMapping `yaml:"mappings"`
+	mockMapping `yaml:",inline"`
+}
+
+type mockMapping struct {
+	Priority *int                `yaml:"priority"`
+	Request  mockMappingRequest  `yaml:"request"`
+	Response mockMappingResponse `yaml:"response"`
+}
+
+type mockMappingRequest struct {
+	Method               string                        `yaml:"method"`
+	Scheme               string                        `yaml:"scheme"`
+	Host                 *mockMappingMatcher           `yaml:"host"`
+	URL                  string                        `yaml:"url"`
+	URLPath              string                        `yaml:"urlPath"`
+	URLPattern           string                        `yaml:"urlPattern"`
+	URLPathPattern       string                        `yaml:"urlPathPattern"`
+	URLPathTemplate      string                        `yaml:"urlPathTemplate"`
+	Headers              map[string]mockMappingMatcher `yaml:"headers"`
+	QueryParameters      map[string]mockMappingMatcher `yaml:"queryParameters"`
+	Cookies              map[string]mockMappingMatcher `yaml:"cookies"`
+	BasicAuthCredentials *struct {
+		Username string `yaml:"username"`
+		Password string `yaml:"password"`
+	} `yaml:"basicAuthCredentials"`
+	BodyPatterns []mockMappingBodyPattern `yaml:"bodyPatterns"`
+}
+
+type mockMappingMatcher struct {
+	EqualTo  *string `yaml:"equalTo"`
+	Matches  *string `yaml:"matches"`
+	Contains *string `yaml:"contains"`
+	Absent   *bool   `yaml:"absent"`
+}
+
+type mockMappingBodyPattern struct {
+	EqualTo     *string     `yaml:"equalTo"`
+	EqualToJSON interface{} `yaml:"equalToJson"`
+	Matches     *string     `yaml:"matches"`
+	Contains    *string     `yaml:"contains"`
+}
+
+type mockMappingResponse struct {
+	Status                 int                          `yaml:"status"`
+	Headers                map[string]mockMappingValues `yaml:"headers"`
+	Cookies                map[string]string            `yaml:"cookies"`
+	Body                   string                       `yaml:"body"`
+	JSONBody               interface{}                  `yaml:"jsonBody"`
+	Base64Body             string                       `yaml:"base64Body"`
+	BodyFileName           string                       `yaml:"bodyFileName"`
+	FixedDelayMilliseconds int64                        `yaml:"fixedDelayMilliseconds"`
+	Times                  int                          `yaml:"times"`
+	Timeout                bool                         `yaml:"timeout"`
+}
+
+// mockMappingValues holds response header values which can be defined as a single value or a list of values
+type mockMappingValues []string
+
+func (v *mockMappingValues) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.SequenceNode {
+		var values []string
+		if err := value.Decode(&values); err != nil {
+			return err
+		}
+		*v = values
+		return nil
+	}
+	var single string
+	if err := value.Decode(&single); err != nil {
+		return err
+	}
+	*v = []string{single}
+	return nil
+}
+
+// MocksFromFile loads mocks from a YAML or JSON mock definition file using the OS filesystem.
+// If the path is a directory all the .json, .yaml and .yml files in the directory are loaded.
+//
+// The definitions follow the WireMock stub mapping format, so the mapping files of WireMock can be used directly.
+// A file contains a single mapping, a list of mappings or an object with a "mappings" field holding the list.
+//
+//	request:
+//	  method: GET                          # http method, matches any method if omitted
+//	  scheme: https                        # optional scheme
+//	  host: { equalTo: example.com }       # optional host
+//	  urlPath: /users                      # path, or use url (path and query), urlPattern, urlPathPattern or urlPathTemplate
+//	  headers:
+//	    Authorization: { matches: "Bearer .+" }
+//	  queryParameters:
+//	    page: { equalTo: "1" }
+//	    debug: { absent: true }
+//	  cookies:
+//	    session: { contains: abc }
+//	  basicAuthCredentials: { username: user, password: pass }
+//	  bodyPatterns:
+//	    - equalToJson: { "name": "jon" }   # also equalTo, matches and contains
+//	response:
+//	  status: 200
+//	  headers: { Content-Type: application/json }
+//	  cookies: { session: abc }            # apitest extension
+//	  jsonBody: { "id": 1 }                # or body, base64Body or bodyFileName
+//	  fixedDelayMilliseconds: 100
+//	  times: 2                             # apitest extension, see MockResponse.Times
+//	  timeout: false                       # apitest extension, see MockResponse.Timeout
+//
+// String matchers support equalTo, matches (a regular expression matching the whole value), contains and absent.
+// The bodyFileName is resolved relative to the directory of the mapping file, falling back to the __files
+// directory next to the mappings directory as used by WireMock. Mappings are ordered by priority when defined.
+func MocksFromFile(path string) ([]*Mock, error) {
+	return MocksFromFS(OSFS{}, path)
+}
+
+// MocksFromFS loads mocks from a YAML or JSON mock definition file using the given filesystem. See MocksFromFile
+func MocksFromFS(fsys fs.FS, name string) ([]*Mock, error) {
+	files := []string{name}
+	if info, err := fs.Stat(fsys, name); err == nil && info.IsDir() {
+		entries, err := fs.ReadDir(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		files = nil
+		for _, entry := range entries {
+			switch strings.ToLower(path.Ext(entry.Name())) {
+			case ".json", ".yaml", ".yml":
+				if !entry.IsDir() {
+					files = append(files, path.Join(name, entry.Name()))
+				}
+			}
+		}
+	}
+
+	var mappings []mockMapping
+	var mappingFiles []string
+	for _, file := range files {
+		fileMappings, err := readMockMappings(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		for range fileMappings {
+			mappingFiles = append(mappingFiles, file)
+		}
+		mappings = append(mappings, fileMappings...)
+	}
+
+	order := make([]int, len(mappings))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return mappings[order[i]].priority() < mappings[order[j]].priority()
+	})
+
+	var mocks []*Mock
+	for _, i := range order {
+		mock, err := mappings[i].toMock(fsys, path.Dir(mappingFiles[i]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid mock definition in %s: %w", mappingFiles[i], err)
+		}
+		mocks = append(mocks, mock)
+	}
+	return mocks, nil
+}
+
+func readMockMappings(fsys fs.FS, name string) ([]mockMapping, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, fmt.Errorf("failed to parse mock definition file %s: %w", name, err)
+	}
+	if len(node.Content) == 0 {
+		return nil, nil
+	}
+
+	if node.Content[0].Kind == yaml.SequenceNode {
+		var mappings []mockMapping
+		if err := node.Decode(&mappings); err != nil {
+			return nil, fmt.Errorf("failed to parse mock definition file %s: %w", name, err)
+		}
+		return mappings, nil
+	}
+
+	var file mockMappingFile
+	if err := node.Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse mock definition file %s: %w", name, err)
+	}
+	if file.Mappings != nil {
+		return file.Mappings, nil
+	}
+	return []mockMapping{file.mockMapping}, nil
+}
+
+// priority returns the priority of the mapping. As in WireMock 1 is the highest priority and the default is 5
+func (m mockMapping) priority() int {
+	if m.Priority == nil {
+		return 5
+	}
+	return *m.Priority
+}
+
+func (m mockMapping) toMock(fsys fs.FS, dir string) (*Mock, error) {
+	mock := NewMock()
+	if err := m.Request.apply(mock.request); err != nil {
+		return nil, err
+	}
+	if err := m.Response.apply(mock.response, fsys, dir); err != nil {
+		return nil, err
+	}
+	return mock, nil
+}
+
+func (r mockMappingRequest) apply(spec *MockRequest) error {
+	spec.method = strings.ToUpper(r.Method)
+	if spec.method == "ANY" {
+		spec.method = ""
+	}
+
+	spec.url = &url.URL{}
+	switch {
+	case r.URL != "":
+		u, err := url.Parse(r.URL)
+		if err != nil {
+			return err
+		}
+		spec.url = &url.URL{Scheme: u.Scheme, Host: u.Host, Path: exactly(u.Path)}
+		query := u.Query()
+		spec.AddMatcher(func(req *http.Request, _ *MockRequest) error {
+			return errorOrNil(reflect.DeepEqual(query, req.URL.Query()), func() string {
+				return fmt.Sprintf("received query %s did not match mock url query %s", req.URL.RawQuery, u.RawQuery)
+			})
+		})
+	case r.URLPath != "":
+		u, err := url.Parse(r.URLPath)
+		if err != nil {
+			return err
+		}
+		spec.url = &url.URL{Scheme: u.Scheme, Host: u.Host, Path: exactly(u.Path)}
+	case r.URLPathTemplate != "":
+		template, _, err := parsePathTemplate(r.URLPathTemplate)
+		if err != nil {
+			return err
+		}
+		spec.url = &url.URL{Path: r.URLPathTemplate}
+		spec.template = template
+	case r.URLPathPattern != "":
+		spec.url = &url.URL{Path: "^(?:" + r.URLPathPattern + ")$"}
+	case r.URLPattern != "":
+		pattern, err := regexp.Compile("^(?:" + r.URLPattern + ")$")
+		if err != nil {
+			return err
+		}
+		spec.AddMatcher(func(req *http.Request, _ *MockRequest) error {
+			return errorOrNil(pattern.MatchString(req.URL.RequestURI()), func() string {
+				return fmt.Sprintf("received url %s did not match mock url pattern %s", req.URL.RequestURI(), r.URLPattern)
+			})
+		})
+	}
+
+	if r.Scheme != "" {
+		spec.url.Scheme = r.Scheme
+	}
+	if r.Host != nil {
+		if r.Host.EqualTo == nil {
+			return errors.New("host only supports the equalTo matcher")
+		}
+		spec.url.Host = *r.Host.EqualTo
+	}
+
+	for _, name := range sortedMatcherKeys(r.Headers) {
+		if err := r.Headers[name].apply(name, spec.Header, spec.HeaderPresent, spec.HeaderNotPresent); err != nil {
+			return fmt.Errorf("header %s: %w", name, err)
+		}
+	}
+
+	for _, name := range sortedMatcherKeys(r.QueryParameters) {
+		if err := r.QueryParameters[name].apply(name, spec.Query, spec.QueryPresent, spec.QueryNotPresent); err != nil {
+			return fmt.Errorf("query parameter %s: %w", name, err)
+		}
+	}
+
+	for _, name := range sortedMatcherKeys(r.Cookies) {
+		matcher := r.Cookies[name]
+		if matcher.EqualTo != nil {
+			spec.Cookie(name, *matcher.EqualTo)
+			continue
+		}
+		if err := matcher.apply(name, cookieRegexpMatcher(spec), spec.CookiePresent, spec.CookieNotPresent); err != nil {
+			return fmt.Errorf("cookie %s: %w", name, err)
+		}
+	}
+
+	if r.BasicAuthCredentials != nil {
+		spec.BasicAuth(r.BasicAuthCredentials.Username, r.BasicAuthCredentials.Password)
+	}
+
+	for _, pattern := range r.BodyPatterns {
+		if err := pattern.apply(spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// apply configures the mock request using the builder functions for the value, presence and absence of a field
+func (m mockMappingMatcher) apply(name string, value func(string, string) *MockRequest, present, notPresent func(string) *MockRequest) error {
+	switch {
+	case m.EqualTo != nil:
+		value(name, exactly(*m.EqualTo))
+	case m.Matches != nil:
+		value(name, "^(?:"+*m.Matches+")$")
+	case m.Contains != nil:
+		value(name, regexp.QuoteMeta(*m.Contains))
+	case m.Absent != nil && *m.Absent:
+		notPresent(name)
+	case m.Absent != nil:
+		present(name)
+	default:
+		return errors.New("expected one of equalTo, matches, contains or absent")
+	}
+	return nil
+}
+
+func cookieRegexpMatcher(spec *MockRequest) func(string, string) *MockRequest {
+	return func(name, expression string) *MockRequest {
+		return spec.AddMatcher(func(req *http.Request, _ *MockRequest) error {
+			cookie, err := req.Cookie(name)
+			if err != nil {
+				return fmt.Errorf("expected cookie with name '%s' not received", name)
+			}
+			matched, err := regexp.MatchString(expression, cookie.Value)
+			return errorOrNil(matched && err == nil, func() string {
+				return fmt.Sprintf("received cookie %s=%s did not match mock cookie value %s", name, cookie.Value, expression)
+			})
+		})
+	}
+}
+
+func (p mockMappingBodyPattern) apply(spec *MockRequest) error {
+	var matcher Matcher
+	switch {
+	case p.EqualToJSON != nil:
+		body, ok := p.EqualToJSON.(string)
+		if !ok {
+			data, err := json.Marshal(p.EqualToJSON)
+			if err != nil {
+				return err
+			}
+			body = string(data)
+		}
+		if spec.body == "" {
+			spec.body = body
+			return nil
+		}
+		matcher = func(req *http.Request, _ *MockRequest) error {
+			return bodyMatcher(req, &MockRequest{body: body})
+		}
+	case p.EqualTo != nil:
+		body := *p.EqualTo
+		if spec.body == "" {
+			spec.body = body
+			return nil
+		}
+		matcher = func(req *http.Request, _ *MockRequest) error {
+			return bodyMatcher(req, &MockRequest{body: body})
+		}
+	case p.Matches != nil || p.Contains != nil:
+		expression := regexp.QuoteMeta(stringValue(p.Contains))
+		if p.Matches != nil {
+			expression = "^(?s:" + *p.Matches + ")$"
+		}
+		if spec.bodyRegexp == "" {
+			spec.bodyRegexp = expression
+			return nil
+		}
+		matcher = func(req *http.Request, _ *MockRequest) error {
+			return bodyRegexpMatcher(req, &MockRequest{bodyRegexp: expression})
+		}
+	default:
+		return errors.New("body patterns support equalTo, equalToJson, matches and contains")
+	}
+	spec.AddMatcher(matcher)
+	return nil
+}
+
+func (r mockMappingResponse) apply(res *MockResponse, fsys fs.FS, dir string) error {
+	if r.Status == 0 {
+		r.Status = http.StatusOK
+	}
+	res.Status(r.Status)
+
+	for _, name := range sortedValuesKeys(r.Headers) {
+		for _, value := range r.Headers[name] {
+			res.Header(name, value)
+		}
+	}
+
+	var cookieNames []string
+	for name := range r.Cookies {
+		cookieNames = append(cookieNames, name)
+	}
+	sort.Strings(cookieNames)
+	for _, name := range cookieNames {
+		res.Cookie(name, r.Cookies[name])
+	}
+
+	switch {
+	case r.JSONBody != nil:
+		data, err := json.Marshal(r.JSONBody)
+		if err != nil {
+			return err
+		}
+		res.Body(string(data))
+	case r.Base64Body != "":
+		data, err := base64.StdEncoding.DecodeString(r.Base64Body)
+		if err != nil {
+			return err
+		}
+		res.Body(string(data))
+	case r.BodyFileName != "":
+		data, err := readBodyFile(fsys, dir, r.BodyFileName)
+		if err != nil {
+			return err
+		}
+		res.Body(string(data))
+	default:
+		res.Body(r.Body)
+	}
+
+	if r.FixedDelayMilliseconds > 0 {
+		res.FixedDelay(r.FixedDelayMilliseconds)
+	}
+	if r.Times > 0 {
+		res.Times(r.Times)
+	}
+	if r.Timeout {
+		res.Timeout()
+	}
+	return nil
+}
+
+func readBodyFile(fsys fs.FS, dir, name string) ([]byte, error) {
+	data, err := fs.ReadFile(fsys, path.Join(dir, name))
+	if err == nil {
+		return data, nil
+	}
+	// WireMock stores body files in the __files directory next to the mappings directory
+	if wireMockData, wireMockErr := fs.ReadFile(fsys, path.Join(path.Dir(dir), "__files", name)); wireMockErr == nil {
+		return wireMockData, nil
+	}
+	return nil, err
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func sortedMatcherKeys(m map[string]mockMappingMatcher) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedValuesKeys(m map[string]mockMappingValues) []string {
+	var keys []string
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,232 @@
+package apitest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestMocksFromFS_LoadsYAMLMapping(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mocks/user.yaml": &fstest.MapFile{Data: []byte(`
+request:
+  method: GET
+  url: http://example.com/user?id=1
+  headers:
+    Authorization:
+      matches: Bearer .+
+  queryParameters:
+    debug:
+      absent: true
+response:
+  status: 200
+  headers:
+    Content-Type: application/json
+  jsonBody:
+    name: jon
+  times: 2
+`)},
+	}
+
+	mocks, err := MocksFromFS(fsys, "mocks/user.yaml")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(mocks))
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/user?id=1", nil)
+	req.Header.Set("Authorization", "Bearer abc")
+	assert.Equal(t, 0, len(mocks[0].Matches(req)))
+	assert.Equal(t, `{"name":"jon"}`, mocks[0].response.body)
+	assert.Equal(t, "application/json", mocks[0].response.headers["Content-Type"][0])
+	assert.Equal(t, 2, mocks[0].response.mock.times)
+
+	req = httptest.NewRequest(http.MethodGet, "http://example.com/user?id=10", nil)
+	req.Header.Set("Authorization", "Bearer abc")
+	assert.True(t, len(mocks[0].Matches(req)) > 0)
+
+	req = httptest.NewRequest(http.MethodGet, "http://example.com/user?id=1&debug=true", nil)
+	req.Header.Set("Authorization", "Bearer abc")
+	assert.True(t, len(mocks[0].Matches(req)) > 0)
+}
+
+func TestMocksFromFS_LoadsWireMockMappingsDirectory(t *testing.T) {
+	fsys := fstest.MapFS{
+		"wiremock/mappings/users.json": &fstest.MapFile{Data: []byte(`{
+  "mappings": [
+    {
+      "request": {"method": "POST", "urlPath": "/users", "bodyPatterns": [{"equalToJson": {"name": "jon"}}]},
+      "response": {"status": 201, "bodyFileName": "created.json"}
+    },
+    {
+      "priority": 1,
+      "request": {"method": "ANY", "urlPathPattern": "/users/[0-9]+"},
+      "response": {"status": 200, "body": "user"}
+    }
+  ]
+}`)},
+		"wiremock/mappings/README.md":   &fstest.MapFile{Data: []byte("not a mapping")},
+		"wiremock/__files/created.json": &fstest.MapFile{Data: []byte(`{"id": 1}`)},
+	}
+
+	mocks, err := MocksFromFS(fsys, "wiremock/mappings")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(mocks))
+	assert.Equal(t, "user", mocks[0].response.body)
+	assert.Equal(t, 0, len(mocks[0].Matches(httptest.NewRequest(http.MethodDelete, "http://example.com/users/12", nil))))
+	assert.True(t, len(mocks[0].Matches(httptest.NewRequest(http.MethodDelete, "http://example.com/users/12/x", nil))) > 0)
+
+	assert.Equal(t, `{"id": 1}`, mocks[1].response.body)
+	assert.Equal(t, http.StatusCreated, mocks[1].response.statusCode)
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/users", strings.NewReader(`{ "name": "jon" }`))
+	assert.Equal(t, 0, len(mocks[1].Matches(req)))
+}
+
+func TestMocksFromFS_LoadsListOfMappings(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mocks.yml": &fstest.MapFile{Data: []byte(`
+- request: {method: GET, urlPath: /a, cookies: {session: {contains: abc}}}
+  response: {body: a}
+- request: {method: GET, urlPattern: "/b\\?page=[0-9]+", basicAuthCredentials: {username: u, password: p}}
+  response: {status: 204}
+`)},
+	}
+
+	mocks, err := MocksFromFS(fsys, "mocks.yml")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(mocks))
+	assert.Equal(t, http.StatusOK, mocks[0].response.statusCode)
+	req := httptest.NewRequest(http.MethodGet, "http://example.com/a", nil)
+	req.Header.Set("Cookie", "session=xabcx")
+	assert.Equal(t, 0, len(mocks[0].Matches(req)))
+
+	req = httptest.NewRequest(http.MethodGet, "http://example.com/b?page=2", nil)
+	req.SetBasicAuth("u", "p")
+	assert.Equal(t, 0, len(mocks[1].Matches(req)))
+	req = httptest.NewRequest(http.MethodGet, "http://example.com/b?page=x", nil)
+	req.SetBasicAuth("u", "p")
+	assert.True(t, len(mocks[1].Matches(req)) > 0)
+}
+
+func TestMocksFromFS_ErrorsOnUnsupportedMatcher(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mock.json": &fstest.MapFile{Data: []byte(`{"request": {"headers": {"Accept": {"doesNotMatch": "x"}}}}`)},
+	}
+
+	_, err := MocksFromFS(fsys, "mock.json")
+
+	assert.Equal(t, "invalid mock definition in mock.json: header Accept: expected one of equalTo, matches, contains or absent", err.Error())
+}
+
+func TestMocksFromFile_UsesOSFilesystem(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mock.json")
+	assert.NoError(t, ioutil.WriteFile(path, []byte(`{"request": {"urlPath": "/a"}, "response": {"body": "a"}}`), 0644))
+
+	mocks, err := MocksFromFile(path)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(mocks))
+	assert.Equal(t, "a", mocks[0].response.body)
+}
+
+func TestApiTest_MocksFromFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mocks/user.yaml": &fstest.MapFile{Data: []byte(`
+request: {method: GET, url: http://example.com/user}
+response: {body: jon, times: 2}
+`)},
+	}
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i < 2; i++ {
+			res, err := http.Get("http://example.com/user")
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			body, _ := ioutil.ReadAll(res.Body)
+			_, _ = w.Write(body)
+		}
+	}
+
+	New().
+		UseFS(fsys).
+		MocksFromFile("mocks/user.yaml").
+		HandlerFunc(handler).
+		Get("/").
+		Expect(t).
+		Body("jonjon").
+		End()
+}
+
+func TestMocksFromFS_URLPathTemplate(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mocks.yml": &fstest.MapFile{Data: []byte(`
+request: {method: GET, urlPathTemplate: "/users/{id}"}
+response: {body: user}
+`)},
+	}
+
+	mocks, err := MocksFromFS(fsys, "mocks.yml")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(mocks[0].Matches(httptest.NewRequest(http.MethodGet, "http://example.com/users/12", nil))))
+	assert.True(t, len(mocks[0].Matches(httptest.NewRequest(http.MethodGet, "http://example.com/users/12/x", nil))) > 0)
+}
+
+func TestMocksFromFS_URLAndURLPathMatchExactly(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mocks.yml": &fstest.MapFile{Data: []byte(`
+- request: {method: GET, urlPath: /users}
+- request: {method: GET, urlPath: /v1.0/users}
+- request: {method: GET, url: "/search?q=a"}
+`)},
+	}
+
+	mocks, err := MocksFromFS(fsys, "mocks.yml")
+	assert.NoError(t, err)
+
+	tests := []struct {
+		mock    *Mock
+		url     string
+		matches bool
+	}{
+		{mocks[0], "http://example.com/users", true},
+		{mocks[0], "http://example.com/users?page=2", true},
+		{mocks[0], "http://example.com/users/10/orders", false},
+		{mocks[0], "http://example.com/api/users", false},
+		{mocks[1], "http://example.com/v1.0/users", true},
+		{mocks[1], "http://example.com/v1x0/users", false},
+		{mocks[2], "http://example.com/search?q=a", true},
+		{mocks[2], "http://example.com/search?q=a&page=2", false},
+		{mocks[2], "http://example.com/search?q=ab", false},
+		{mocks[2], "http://example.com/search", false},
+		{mocks[2], "http://example.com/searches?q=a", false},
+	}
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			errs := test.mock.Matches(httptest.NewRequest(http.MethodGet, test.url, nil))
+			assert.Equal(t, test.matches, len(errs) == 0)
+		})
+	}
+}
+
+func TestMocksFromFS_ReadsBodyFileNextToRootMapping(t *testing.T) {
+	fsys := fstest.MapFS{
+		"mapping.json":        &fstest.MapFile{Data: []byte(`{"request": {"urlPath": "/a"}, "response": {"bodyFileName": "a.json"}}`)},
+		"__files/a.json":      &fstest.MapFile{Data: []byte(`{"a": 1}`)},
+		"mappings/users.json": &fstest.MapFile{Data: []byte(`{"request": {"urlPath": "/b"}, "response": {"bodyFileName": "b.json"}}`)},
+		"__files/b.json":      &fstest.MapFile{Data: []byte(`{"b": 1}`)},
+	}
+
+	mocks, err := MocksFromFS(fsys, "mapping.json")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a": 1}`, mocks[0].response.body)
+
+	mocks, err = MocksFromFS(fsys, "mappings")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"b": 1}`, mocks[0].response.body)
+}
@@ -0,0 +1,166 @@
+package apitest
+
+import (
+	"bytes"
+	"crypto/tls"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+)
+
+// NewMockProxy starts a http forward proxy that responds to requests using the given mocks, so that clients which
+// build their own transport, such as third party SDKs, or subprocesses can be mocked by configuring them to use the
+// proxy. Unlike NewMockServer the scheme and host of the mocks are matched. HTTPS requests are intercepted using
+// certificates issued by an ephemeral certificate authority, which clients must trust, see Client and CACertificate.
+// The proxy must be closed once the test is finished
+func NewMockProxy(mocks ...*Mock) *MockServer {
+	return startMockProxy(newMockServer(mocks, true))
+}
+
+// NewMockProxyWithCassette starts a mock proxy that forwards the requests which do not match any mock to the real
+// network and records the interactions to the cassette, which is saved when the proxy is closed. If the cassette
+// was already recorded the saved interactions are replayed as mocks instead, see Cassette
+func NewMockProxyWithCassette(cassette *Cassette, mocks ...*Mock) *MockServer {
+	if !cassette.isRecording() {
+		cassetteMocks, err := cassette.Mocks()
+		if err != nil {
+			panic(err)
+		}
+		return NewMockProxy(append(append([]*Mock{}, mocks...), cassetteMocks...)...)
+	}
+	s := newMockServer(mocks, true)
+	s.cassette = cassette
+	s.forwardTransport = &http.Transport{}
+	return startMockProxy(s)
+}
+
+func startMockProxy(s *MockServer) *MockServer {
+	s.proxy = true
+	s.ca = TestCA()
+	s.server.Start()
+	s.URL = s.server.URL
+	s.tunnels = &tunnelListener{conns: make(chan net.Conn), closed: make(chan struct{}), addr: s.server.Listener.Addr()}
+	s.tunnelServer = &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			req.URL.Scheme = "https"
+			req.URL.Host = req.Host
+			s.ServeHTTP(w, req)
+		}),
+		// handshakes fail when a client does not trust the certificate authority, which is reported to the client
+		ErrorLog: log.New(ioutil.Discard, "", 0),
+	}
+	go func() {
+		_ = s.tunnelServer.Serve(s.tunnels)
+	}()
+	return s
+}
+
+// ProxyEnv returns the environment variables that configure clients, such as a subprocess, to send http and https
+// requests through the proxy. Go clients read these variables once per process and never proxy requests to
+// localhost, so prefer Client for clients running in the test process
+func (s *MockServer) ProxyEnv() []string {
+	return []string{
+		"HTTP_PROXY=" + s.URL,
+		"HTTPS_PROXY=" + s.URL,
+		"http_proxy=" + s.URL,
+		"https_proxy=" + s.URL,
+	}
+}
+
+// CACertificate returns the PEM encoded certificate of the authority that issues the certificates of the server, or
+// nil if there is none
+func (s *MockServer) CACertificate() []byte {
+	if s.ca == nil {
+		return nil
+	}
+	return s.ca.CertificatePEM()
+}
+
+// serveConnect establishes a tunnel for the CONNECT request and serves the https requests sent through the tunnel
+func (s *MockServer) serveConnect(w http.ResponseWriter, req *http.Request) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "CONNECT is not supported", http.StatusInternalServerError)
+		return
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	if _, err := conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n")); err != nil {
+		_ = conn.Close()
+		return
+	}
+
+	host, _, err := net.SplitHostPort(req.Host)
+	if err != nil {
+		host = req.Host
+	}
+	s.tunnels.serve(tls.Server(conn, s.ca.serverConfig(host)))
+}
+
+// forward sends the request to the real network and records the interaction to the cassette
+func (s *MockServer) forward(w http.ResponseWriter, req *http.Request) {
+	body, _ := ioutil.ReadAll(req.Body)
+	out := req.Clone(req.Context())
+	out.RequestURI = ""
+	out.Header.Del("Proxy-Connection")
+	out.Header.Del("Proxy-Authorization")
+	out.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	res, err := s.forwardTransport.RoundTrip(out)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer res.Body.Close()
+	out.Body = ioutil.NopCloser(bytes.NewReader(body))
+	s.cassette.record(out, res)
+
+	for key, values := range res.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(res.StatusCode)
+	_, _ = io.Copy(w, res.Body)
+}
+
+// tunnelListener accepts the connections of the tunnels established by CONNECT requests
+type tunnelListener struct {
+	conns  chan net.Conn
+	closed chan struct{}
+	once   sync.Once
+	addr   net.Addr
+}
+
+func (l *tunnelListener) serve(conn net.Conn) {
+	select {
+	case l.conns <- conn:
+	case <-l.closed:
+		_ = conn.Close()
+	}
+}
+
+func (l *tunnelListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+func (l *tunnelListener) Close() error {
+	l.once.Do(func() {
+		close(l.closed)
+	})
+	return nil
+}
+
+func (l *tunnelListener) Addr() net.Addr {
+	return l.addr
+}
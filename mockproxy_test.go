@@ -0,0 +1,119 @@
+package apitest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMockProxy_ServesHTTPAndHTTPSMocks(t *testing.T) {
+	proxy := NewMockProxy(
+		NewMock().Get("http://example.com/user").RespondWith().Body("http").End(),
+		NewMock().Get("https://api.example.com/user").Query("id", "1").RespondWith().Body("https").End(),
+	)
+	client := proxy.Client()
+
+	res, err := client.Get("http://example.com/user")
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, "http", string(body))
+
+	res, err = client.Get("https://api.example.com/user?id=1")
+	assert.NoError(t, err)
+	body, _ = ioutil.ReadAll(res.Body)
+	assert.Equal(t, "https", string(body))
+	assert.Equal(t, "api.example.com", res.TLS.PeerCertificates[0].Subject.CommonName)
+	assert.NoError(t, proxy.Close())
+}
+
+func TestMockProxy_MatchesSchemeAndHost(t *testing.T) {
+	proxy := NewMockProxy(NewMock().Get("https://api.example.com/user").RespondWith().Body("jon").End())
+
+	res, err := proxy.Client().Get("https://example.com/user")
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	unmatched := proxy.UnmatchedRequests()
+	assert.Equal(t, 1, len(unmatched))
+	assert.Equal(t, "https://example.com/user", unmatched[0].URL)
+	assert.True(t, strings.Contains(unmatched[0].Reason, "received host example.com did not match mock host api.example.com"))
+	assert.True(t, proxy.Close() != nil)
+}
+
+func TestMockProxy_CACertificate(t *testing.T) {
+	proxy := NewMockProxy(NewMock().Get("https://api.example.com/user").RespondWith().Body("jon").End())
+	defer proxy.Close()
+	pool := x509.NewCertPool()
+	assert.True(t, pool.AppendCertsFromPEM(proxy.CACertificate()))
+	proxyURL, _ := url.Parse(proxy.URL)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL), TLSClientConfig: &tls.Config{RootCAs: pool}}}
+
+	res, err := client.Get("https://api.example.com/user")
+
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, "jon", string(body))
+	untrusted := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+	_, err = untrusted.Get("https://api.example.com/user")
+	assert.True(t, err != nil)
+}
+
+func TestMockProxy_ProxyEnv(t *testing.T) {
+	proxy := NewMockProxy()
+	defer proxy.Close()
+
+	env := proxy.ProxyEnv()
+
+	assert.Equal(t, "HTTP_PROXY="+proxy.URL, env[0])
+	assert.Equal(t, "HTTPS_PROXY="+proxy.URL, env[1])
+	server := NewMockServer()
+	defer server.Close()
+	assert.True(t, server.CACertificate() == nil)
+}
+
+func TestMockProxy_RecordsAndReplaysCassette(t *testing.T) {
+	upstreamCalls := 0
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamCalls++
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "text/plain")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("created " + string(body)))
+	}))
+	path := filepath.Join(t.TempDir(), "proxy.json")
+
+	proxy := NewMockProxyWithCassette(NewCassette(path), NewMock().Get("http://example.com/user").RespondWith().Body("jon").End())
+	res, err := proxy.Client().Post(upstream.URL+"/users", "text/plain", strings.NewReader("jon"))
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Equal(t, "created jon", string(body))
+	res, err = proxy.Client().Get("http://example.com/user")
+	assert.NoError(t, err)
+	body, _ = ioutil.ReadAll(res.Body)
+	assert.Equal(t, "jon", string(body))
+	assert.NoError(t, proxy.Close())
+	upstream.Close()
+
+	cassette := NewCassette(path)
+	_, err = cassette.Mocks()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(cassette.Interactions()))
+	assert.Equal(t, upstream.URL+"/users", cassette.Interactions()[0].Request.URL)
+	assert.Equal(t, "jon", cassette.Interactions()[0].Request.Body)
+
+	proxy = NewMockProxyWithCassette(NewCassette(path))
+	defer proxy.Close()
+	res, err = proxy.Client().Post(upstream.URL+"/users", "text/plain", strings.NewReader("jon"))
+	assert.NoError(t, err)
+	body, _ = ioutil.ReadAll(res.Body)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Equal(t, "created jon", string(body))
+	assert.Equal(t, 1, upstreamCalls)
+}
@@ -2,6 +2,7 @@ package apitest
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,6 +16,8 @@ import (
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"text/template"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
@@ -30,6 +33,14 @@ type Transport struct {
 	httpClient               *http.Client
 	observers                []Observe
 	apiTest                  *APITest
+	cassette                 *Cassette
+	scenarios                *Scenarios
+	passthrough              *passthrough
+	denied                   *deniedRequests
+	upstreams                *upstreams
+	calls                    mockCalls
+	sequence                 int64
+	inFlight                 int64
 }
 
 func newTransport(
@@ -57,12 +68,15 @@ func newTransport(
 }
 
 type unmatchedMockError struct {
-	errors map[int][]error
+	errors     map[int][]error
+	candidates map[int]mockCandidate
+	request    *receivedRequest
 }
 
 func newUnmatchedMockError() *unmatchedMockError {
 	return &unmatchedMockError{
-		errors: map[int][]error{},
+		errors:     map[int][]error{},
+		candidates: map[int]mockCandidate{},
 	}
 }
 
@@ -71,33 +85,106 @@ func (u *unmatchedMockError) addErrors(mockNumber int, errors ...error) *unmatch
 	return u
 }
 
-// Error implementation of in-built error human readable string function
+// addCandidate records the errors of a mock that did not match and the number of its configured matchers that passed
+func (u *unmatchedMockError) addCandidate(mockNumber int, mock *Mock, passed int, errors ...error) *unmatchedMockError {
+	u.candidates[mockNumber] = mockCandidate{mock: mock, passed: passed}
+	return u.addErrors(mockNumber, errors...)
+}
+
+// Error implementation of in-built error human readable string function.
+// Mocks are listed closest match first, where the closest matches are described field by field
 func (u *unmatchedMockError) Error() string {
 	var strBuilder strings.Builder
 	strBuilder.WriteString("received request did not match any mocks\n\n")
-	for _, mockNumber := range u.orderedMockKeys() {
-		strBuilder.WriteString(fmt.Sprintf("Mock %d mismatches:\n", mockNumber))
-		for _, err := range u.errors[mockNumber] {
-			strBuilder.WriteString("• ")
-			strBuilder.WriteString(err.Error())
-			strBuilder.WriteString("\n")
+	if u.request != nil {
+		strBuilder.WriteString(u.request.String())
+		strBuilder.WriteString("\n\n")
+	}
+
+	var furthest []int
+	for i, mockNumber := range u.orderedMockKeys() {
+		candidate, ok := u.candidates[mockNumber]
+		if !ok || u.request == nil {
+			strBuilder.WriteString(fmt.Sprintf("Mock %d mismatches:\n", mockNumber))
+			writeMismatches(&strBuilder, u.errors[mockNumber])
+			continue
+		}
+		if i >= closestMatchesDescribed {
+			furthest = append(furthest, mockNumber)
+			continue
+		}
+		strBuilder.WriteString(fmt.Sprintf("Mock %d %s passed %d of %d checks:\n",
+			mockNumber, candidate.mock.request.describe(), candidate.passed, candidate.mock.request.checks()))
+		strBuilder.WriteString(u.request.compare(candidate.mock.request))
+		writeMismatchSummaries(&strBuilder, u.errors[mockNumber])
+	}
+
+	if len(furthest) > 0 {
+		strBuilder.WriteString("Other mocks:\n")
+		for _, mockNumber := range furthest {
+			candidate := u.candidates[mockNumber]
+			strBuilder.WriteString(fmt.Sprintf("• Mock %d %s passed %d of %d checks\n",
+				mockNumber, candidate.mock.request.describe(), candidate.passed, candidate.mock.request.checks()))
 		}
 		strBuilder.WriteString("\n")
 	}
 	return strBuilder.String()
 }
 
+// writeMismatchSummaries writes the first line of each error, omitting details such as diffs which are already
+// included in the comparison of the request to the mock
+func writeMismatchSummaries(strBuilder *strings.Builder, errs []error) {
+	var summaries []error
+	for _, err := range errs {
+		summaries = append(summaries, errors.New(strings.SplitN(err.Error(), "\n", 2)[0]))
+	}
+	writeMismatches(strBuilder, summaries)
+}
+
+func writeMismatches(strBuilder *strings.Builder, errs []error) {
+	for _, err := range errs {
+		strBuilder.WriteString("• ")
+		strBuilder.WriteString(err.Error())
+		strBuilder.WriteString("\n")
+	}
+	strBuilder.WriteString("\n")
+}
+
+// orderedMockKeys orders the mocks by the number of configured matchers passed, then by the number of mismatches
 func (u *unmatchedMockError) orderedMockKeys() []int {
 	var mockKeys []int
 	for mockKey := range u.errors {
 		mockKeys = append(mockKeys, mockKey)
 	}
-	sort.Ints(mockKeys)
+	sort.Slice(mockKeys, func(i, j int) bool {
+		a, b := mockKeys[i], mockKeys[j]
+		if u.candidates[a].passed != u.candidates[b].passed {
+			return u.candidates[a].passed > u.candidates[b].passed
+		}
+		if len(u.errors[a]) != len(u.errors[b]) {
+			return len(u.errors[a]) < len(u.errors[b])
+		}
+		return a < b
+	})
 	return mockKeys
 }
 
 // RoundTrip implementation intended to match a given expected mock request or throw an error with a list of reasons why no match was found.
 func (r *Transport) RoundTrip(req *http.Request) (mockResponse *http.Response, matchErrors error) {
+	// deferred first so that the request is in flight until the observers have received it
+	atomic.AddInt64(&r.inFlight, 1)
+	defer atomic.AddInt64(&r.inFlight, -1)
+
+	req = req.WithContext(context.WithValue(req.Context(), mockRequestStartedKey{}, time.Now().UTC()))
+
+	// body faults are applied after the response has been observed so that reading the body fails for the caller only
+	var applyBodyFault func()
+	defer func() {
+		if applyBodyFault != nil {
+			applyBodyFault()
+		}
+	}()
+
 	if r.debugEnabled {
 		defer func() {
 			debugMock(mockResponse, req)
@@ -112,29 +199,145 @@ func (r *Transport) RoundTrip(req *http.Request) (mockResponse *http.Response, m
 		}()
 	}
 
-	matchedResponse, matchErrors := matches(req, r.mocks)
+	matchedResponse, matchErrors := matches(req, r.mocks, r.scenarios)
 	if matchErrors == nil {
-		res := buildResponseFromMock(matchedResponse)
-		res.Request = req
-
 		if matchedResponse.timeout {
+			r.recordCall(matchedResponse.mock, req, nil)
 			return nil, timeoutError{}
 		}
 
-		if r.mockResponseDelayEnabled && matchedResponse.fixedDelayMillis > 0 {
-			time.Sleep(time.Duration(matchedResponse.fixedDelayMillis) * time.Millisecond)
+		if r.mockResponseDelayEnabled {
+			if err := sleepContext(req.Context(), matchedResponse.delay()); err != nil {
+				r.recordCall(matchedResponse.mock, req, nil)
+				return nil, err
+			}
+		}
+
+		fault := matchedResponse.nextFault()
+		if err := fault.roundTripError(req); err != nil {
+			r.recordCall(matchedResponse.mock, req, nil)
+			return nil, err
+		}
+
+		res, err := matchedResponse.respond(req)
+		if err != nil {
+			r.recordCall(matchedResponse.mock, req, nil)
+			return nil, err
 		}
+		res.Request = req
+		r.recordCall(matchedResponse.mock, req, res)
+		applyBodyFault = func() {
+			fault.applyToResponse(res)
+			matchedResponse.dripFeed.applyToResponse(res, req.Context())
+		}
+		return res, nil
+	}
 
+	if handler, host := r.upstreams.handler(req); handler != nil {
+		res := serveUpstream(handler, host, req)
+		r.recordCall(nil, req, res)
 		return res, nil
 	}
 
+	if r.cassette != nil {
+		// the body is buffered as the real transport consumes and closes it before the interaction is recorded
+		var body []byte
+		if req.Body != nil {
+			body, _ = ioutil.ReadAll(req.Body)
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		res, err := r.roundTripNative(req)
+		if err != nil {
+			return nil, err
+		}
+		if req.Body != nil {
+			req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		}
+		r.cassette.record(req, res)
+		return res, nil
+	}
+
+	if r.passthrough.allows(req) {
+		return r.roundTripNative(req)
+	}
+
 	if r.debugEnabled {
 		fmt.Printf("failed to match mocks. Errors: %s\n", matchErrors)
 	}
 
+	if r.denied != nil {
+		r.denied.add(req)
+	}
+
+	// the observers receive the match error with the request so that the report can show the unmatched request
+	req = req.WithContext(context.WithValue(req.Context(), mockMatchErrorKey{}, matchErrors))
 	return nil, matchErrors
 }
 
+// passthrough defines the hosts of unmatched requests that are forwarded to the real network
+type passthrough struct {
+	hosts []string
+	all   bool
+}
+
+func newPassthrough(hosts []string) *passthrough {
+	return &passthrough{hosts: hosts}
+}
+
+// allows returns true if the request should be sent to the real network
+func (p *passthrough) allows(req *http.Request) bool {
+	if p == nil {
+		return false
+	}
+	if p.all {
+		return true
+	}
+	for _, host := range p.hosts {
+		if host == req.URL.Host || host == req.URL.Hostname() {
+			return true
+		}
+	}
+	return false
+}
+
+// recordCall records the call on the matched mock and the transport
+func (r *Transport) recordCall(mock *Mock, req *http.Request, res *http.Response) {
+	call := newMockCall(req, res)
+	call.sequence = atomic.AddInt64(&r.sequence, 1)
+	if mock != nil {
+		mock.calls.add(call)
+	}
+	r.calls.add(call)
+}
+
+// roundTripNative sends the request to the real network using the transport that was replaced by Hijack
+func (r *Transport) roundTripNative(req *http.Request) (*http.Response, error) {
+	if r.nativeTransport != nil {
+		return r.nativeTransport.RoundTrip(req)
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+type mockRequestStartedKey struct{}
+
+// mockRequestStarted returns the time the transport received the given mock request, or the zero time if unknown
+func mockRequestStarted(req *http.Request) time.Time {
+	if started, ok := req.Context().Value(mockRequestStartedKey{}).(time.Time); ok {
+		return started
+	}
+	return time.Time{}
+}
+
+type mockMatchErrorKey struct{}
+
+// mockMatchError returns the error describing why the given mock request did not match any mocks, or nil if it matched
+func mockMatchError(req *http.Request) error {
+	if err, ok := req.Context().Value(mockMatchErrorKey{}).(error); ok {
+		return err
+	}
+	return nil
+}
+
 func debugMock(res *http.Response, req *http.Request) {
 	requestDump, err := httputil.DumpRequestOut(req, true)
 	if err == nil {
@@ -169,19 +372,113 @@ func (r *Transport) Reset() {
 	http.DefaultTransport = r.nativeTransport
 }
 
+// respond builds the response of the mock to the given matched request
+func (r *MockResponse) respond(req *http.Request) (*http.Response, error) {
+	var pathValues map[string]string
+	if r.mock != nil {
+		pathValues = r.mock.request.pathValues(req)
+		req = r.mock.request.withPathValues(req)
+	}
+
+	if r.failFirst != nil && r.failFirst.next() {
+		return r.failFirst.response(), nil
+	}
+
+	if r.respondFunc != nil {
+		res, err := r.respondFunc(req)
+		if err != nil {
+			return nil, err
+		}
+		if res == nil {
+			return nil, errors.New("mock respond func returned a nil response")
+		}
+		if res.Body == nil {
+			res.Body = http.NoBody
+		}
+		return res, nil
+	}
+
+	if r.bodyTemplate == nil {
+		return buildResponseFromMock(r), nil
+	}
+
+	data, err := newMockTemplateData(req, pathValues)
+	if err != nil {
+		return nil, err
+	}
+	var body bytes.Buffer
+	if err := r.bodyTemplate.Execute(&body, data); err != nil {
+		return nil, err
+	}
+	return buildResponseFromMockWithBody(r, body.String()), nil
+}
+
+// MockTemplateData is the data available to the template defined by MockResponse.BodyTemplate
+type MockTemplateData struct {
+	Method string
+	URL    *url.URL
+	Path   string
+	// PathSegments are the segments of the path, e.g. [users 1] for /users/1
+	PathSegments []string
+	// PathValues are the values captured by the wildcards of a mock path template, e.g. map[id:1] for /users/{id}
+	PathValues map[string]string
+	Query      url.Values
+	Headers    http.Header
+	Cookies    map[string]string
+	Body       string
+	// JSON is the body decoded from JSON, or nil if the body is not valid JSON
+	JSON interface{}
+}
+
+func newMockTemplateData(req *http.Request, pathValues map[string]string) (MockTemplateData, error) {
+	data := MockTemplateData{
+		Method:     req.Method,
+		URL:        req.URL,
+		Path:       req.URL.Path,
+		PathValues: pathValues,
+		Query:      req.URL.Query(),
+		Headers:    req.Header,
+		Cookies:    map[string]string{},
+	}
+
+	if path := strings.Trim(req.URL.Path, "/"); path != "" {
+		data.PathSegments = strings.Split(path, "/")
+	}
+
+	for _, cookie := range req.Cookies() {
+		data.Cookies[cookie.Name] = cookie.Value
+	}
+
+	if req.Body != nil {
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			return data, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		data.Body = string(body)
+		if json.Valid(body) {
+			_ = json.Unmarshal(body, &data.JSON)
+		}
+	}
+	return data, nil
+}
+
 func buildResponseFromMock(mockResponse *MockResponse) *http.Response {
 	if mockResponse == nil {
 		return nil
 	}
+	return buildResponseFromMockWithBody(mockResponse, mockResponse.body)
+}
 
+func buildResponseFromMockWithBody(mockResponse *MockResponse, body string) *http.Response {
 	mockResponse.mu.RLock() // Lock for reading
 	contentTypeHeader := mockResponse.headers["Content-Type"]
 	var contentType string
 
 	// if the content type isn't set and the body contains json, set content type as json
-	if len(mockResponse.body) > 0 {
+	if len(body) > 0 {
 		if len(contentTypeHeader) == 0 {
-			if json.Valid([]byte(mockResponse.body)) {
+			if json.Valid([]byte(body)) {
 				contentType = "application/json"
 			} else {
 				contentType = "text/plain"
@@ -194,12 +491,12 @@ func buildResponseFromMock(mockResponse *MockResponse) *http.Response {
 	mockResponse.mu.RUnlock() // Unlock after reading
 
 	res := &http.Response{
-		Body:          ioutil.NopCloser(strings.NewReader(mockResponse.body)),
+		Body:          ioutil.NopCloser(strings.NewReader(body)),
 		Header:        mockResponse.headers,
 		StatusCode:    mockResponse.statusCode,
 		ProtoMajor:    1,
 		ProtoMinor:    1,
-		ContentLength: int64(len(mockResponse.body)),
+		ContentLength: int64(len(body)),
 	}
 
 	for _, cookie := range mockResponse.cookies {
@@ -227,18 +524,161 @@ type Mock struct {
 	debugStandalone bool
 	times           int
 	timesSet        bool
+	scenario        string
+	requiredState   string
+	newState        string
 	anyTimesSet     bool
+	callRange       *callRange
+	calls           *mockCalls
+}
+
+// callRange is the expected number of calls of a mock defined by AtLeast, AtMost, Between or Never.
+// A negative max means there is no upper bound
+type callRange struct {
+	min int
+	max int
+}
+
+func (c callRange) contains(calls int) bool {
+	return calls >= c.min && (c.max < 0 || calls <= c.max)
+}
+
+func (c callRange) String() string {
+	switch {
+	case c.max < 0:
+		return fmt.Sprintf("at least %d", c.min)
+	case c.min == c.max:
+		return fmt.Sprintf("%d", c.min)
+	case c.min == 0:
+		return fmt.Sprintf("at most %d", c.max)
+	default:
+		return fmt.Sprintf("between %d and %d", c.min, c.max)
+	}
+}
+
+// unlimited returns true if the mock can be matched any number of times
+func (m *Mock) unlimited() bool {
+	return m.anyTimesSet || m.callRange != nil
 }
 
-// Matches checks whether the given request matches the mock
+// MockCall is a request matched by a mock together with the response served by the mock. The response is nil
+// if the mock timed out or failed to build a response
+type MockCall struct {
+	Request   *http.Request
+	Response  *http.Response
+	Timestamp time.Time
+	sequence  int64
+}
+
+// mockCalls holds the calls of a mock, shared between the mock and its copies
+type mockCalls struct {
+	mu    sync.Mutex
+	calls []MockCall
+}
+
+func (c *mockCalls) add(call MockCall) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, call)
+}
+
+// list returns copies of the calls ordered by the time they were received, so that the request and response bodies
+// can be read again
+func (c *mockCalls) list() []MockCall {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var calls []MockCall
+	for _, call := range c.calls {
+		calls = append(calls, call.copy())
+	}
+	sort.SliceStable(calls, func(i, j int) bool {
+		return calls[i].Timestamp.Before(calls[j].Timestamp)
+	})
+	return calls
+}
+
+func (c *mockCalls) count() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.calls)
+}
+
+func (c *mockCalls) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = nil
+}
+
+func newMockCall(req *http.Request, res *http.Response) MockCall {
+	call := MockCall{
+		Request:   copyHttpRequest(req),
+		Response:  copyHttpResponse(res),
+		Timestamp: mockRequestStarted(req),
+	}
+	if call.Timestamp.IsZero() {
+		call.Timestamp = time.Now().UTC()
+	}
+	return call
+}
+
+func (c MockCall) copy() MockCall {
+	return MockCall{
+		Request:   copyHttpRequest(c.Request),
+		Response:  copyHttpResponse(c.Response),
+		Timestamp: c.Timestamp,
+		sequence:  c.sequence,
+	}
+}
+
+func (c MockCall) String() string {
+	if c.Response == nil {
+		return fmt.Sprintf("%s %s -> no response", c.Request.Method, c.Request.URL)
+	}
+	return fmt.Sprintf("%s %s -> %d", c.Request.Method, c.Request.URL, c.Response.StatusCode)
+}
+
+func formatMockCalls(calls []MockCall) string {
+	if len(calls) == 0 {
+		return "no mock calls received"
+	}
+	var b strings.Builder
+	b.WriteString("mock calls received:")
+	for i, call := range calls {
+		b.WriteString(fmt.Sprintf("\n%d. %s", i+1, call))
+	}
+	return b.String()
+}
+
+// Calls returns the requests matched by the mock and the responses served in the order they were received.
+// The calls are cleared when an API test using the mock is started
+func (m *Mock) Calls() []MockCall {
+	return m.calls.list()
+}
+
+// Matches checks whether the given request matches the mock. Values captured by a mock path template are available
+// to the matchers from http.Request.PathValue
 func (m *Mock) Matches(req *http.Request) []error {
+	errs, _ := m.match(req)
+	return errs
+}
+
+// match returns the errors of the matchers that failed and the number of configured matchers that passed
+func (m *Mock) match(req *http.Request) ([]error, int) {
+	matchedReq := m.request.withPathValues(req)
+	defer func() {
+		req.Body = matchedReq.Body // matchers reading the body replace it so that it can be read again
+	}()
+
 	var errs []error
-	for _, matcher := range m.request.matchers {
-		if matcherError := matcher(req, m.request); matcherError != nil {
+	passed := 0
+	for i, matcher := range m.request.matchers {
+		if matcherError := matcher(matchedReq, m.request); matcherError != nil {
 			errs = append(errs, matcherError)
+		} else if m.request.configures(i) {
+			passed++
 		}
 	}
-	return errs
+	return errs, passed
 }
 
 func (m *Mock) copy() *Mock {
@@ -250,6 +690,7 @@ func (m *Mock) copy() *Mock {
 	newMock.request = &req
 
 	newMock.response = m.response.deepCopy()
+	newMock.response.mock = &newMock
 
 	return &newMock
 }
@@ -258,6 +699,7 @@ func (m *Mock) copy() *Mock {
 type MockRequest struct {
 	mock               *Mock
 	url                *url.URL
+	template           *pathTemplate
 	method             string
 	headers            map[string][]string
 	basicAuthUsername  string
@@ -280,30 +722,111 @@ type MockRequest struct {
 
 // UnmatchedMock exposes some information about mocks that failed to match a request
 type UnmatchedMock struct {
-	URL url.URL
+	Method  string
+	URL     url.URL
+	Headers map[string][]string
+	Query   map[string][]string
+	Body    string
+	// Times is the number of calls expected by the mock, or the minimum number of calls if the mock defines a range
+	Times int
+	// Calls is the number of calls received by the mock
+	Calls int
+}
+
+func (m UnmatchedMock) String() string {
+	if m.Calls == 0 {
+		return fmt.Sprintf("%s %s was not invoked", m.Method, m.URL.String())
+	}
+	return fmt.Sprintf("%s %s expected %d calls but received %d", m.Method, m.URL.String(), m.Times, m.Calls)
+}
+
+func formatUnmatchedMocks(unmatched []UnmatchedMock) string {
+	var b strings.Builder
+	b.WriteString("mocks were not used:")
+	for i, mock := range unmatched {
+		b.WriteString(fmt.Sprintf("\n%d. %s", i+1, mock))
+	}
+	return b.String()
+}
+
+// unmatchedMocks returns the mocks that received fewer calls than expected. The copies of a mock made for each of
+// its expected calls are reported as a single mock
+func unmatchedMocks(mocks []*Mock) []UnmatchedMock {
+	var declared []*Mock
+	copies := map[*mockCalls]int{}
+	for _, mock := range mocks {
+		if copies[mock.calls] == 0 {
+			declared = append(declared, mock)
+		}
+		copies[mock.calls]++
+	}
+
+	var unmatched []UnmatchedMock
+	for _, mock := range declared {
+		times := copies[mock.calls]
+		switch {
+		case mock.callRange != nil:
+			times = mock.callRange.min
+		case mock.anyTimesSet:
+			times = 0
+		}
+		calls := mock.calls.count()
+		if calls >= times && !(mock.anyTimesSet && calls == 0) {
+			continue
+		}
+
+		unmatchedMock := UnmatchedMock{
+			Method:  mock.request.method,
+			Headers: mock.request.headers,
+			Query:   mock.request.query,
+			Body:    mock.request.body,
+			Times:   times,
+			Calls:   calls,
+		}
+		if mock.request.url != nil {
+			unmatchedMock.URL = *mock.request.url
+		}
+		unmatched = append(unmatched, unmatchedMock)
+	}
+	return unmatched
 }
 
 // MockResponse represents the http response side of a mock interaction
 type MockResponse struct {
-	mock             *Mock
-	timeout          bool
-	headers          map[string][]string
-	cookies          []*Cookie
-	body             string
-	statusCode       int
-	fixedDelayMillis int64
-	mu               sync.RWMutex // Add a mutex for thread-safe access
+	mock              *Mock
+	timeout           bool
+	headers           map[string][]string
+	cookies           []*Cookie
+	body              string
+	statusCode        int
+	fixedDelayMillis  int64
+	bodyTemplate      *template.Template
+	respondFunc       func(*http.Request) (*http.Response, error)
+	fault             Fault
+	randomFault       *randomFault
+	dripFeed          *dripFeed
+	delayDistribution *delayDistribution
+	graphQL           *GraphQLResponseBody
+	failFirst         *failFirst
+	mu                sync.RWMutex // Add a mutex for thread-safe access
 }
 
 func (r *MockResponse) deepCopy() *MockResponse {
 	newResponse := &MockResponse{
-		timeout:          r.timeout,
-		headers:          make(map[string][]string),
-		cookies:          make([]*Cookie, len(r.cookies)),
-		body:             r.body,
-		statusCode:       r.statusCode,
-		fixedDelayMillis: r.fixedDelayMillis,
-		mu:               sync.RWMutex{},
+		timeout:           r.timeout,
+		headers:           make(map[string][]string),
+		cookies:           make([]*Cookie, len(r.cookies)),
+		body:              r.body,
+		statusCode:        r.statusCode,
+		fixedDelayMillis:  r.fixedDelayMillis,
+		bodyTemplate:      r.bodyTemplate,
+		respondFunc:       r.respondFunc,
+		fault:             r.fault,
+		randomFault:       r.randomFault,
+		dripFeed:          r.dripFeed,
+		delayDistribution: r.delayDistribution,
+		failFirst:         r.failFirst,
+		mu:                sync.RWMutex{},
 	}
 
 	for k, v := range r.headers {
@@ -317,14 +840,32 @@ func (r *MockResponse) deepCopy() *MockResponse {
 		newResponse.cookies[i] = &newCookie
 	}
 
+	if r.graphQL != nil {
+		graphQL := *r.graphQL
+		newResponse.graphQL = &graphQL
+	}
+
 	return newResponse
 }
 
 // StandaloneMocks for using mocks outside of API tests context
 type StandaloneMocks struct {
-	mocks      []*Mock
-	httpClient *http.Client
-	debug      bool
+	mocks       []*Mock
+	httpClient  *http.Client
+	debug       bool
+	cassette    *Cassette
+	scenarios   *Scenarios
+	passthrough *passthrough
+	delay       bool
+	name        string
+	meta        map[string]interface{}
+	reporter    ReportFormatter
+	recorder    *Recorder
+	expanded    []*Mock
+	started     time.Time
+	mu          sync.Mutex
+	unmatched   []UnmatchedRequest
+	recorded    []*mockInteraction
 }
 
 // NewStandaloneMocks create a series of StandaloneMocks
@@ -346,26 +887,209 @@ func (r *StandaloneMocks) Debug() *StandaloneMocks {
 	return r
 }
 
+// EnableMockResponseDelay turns on mock response delays (defaults to OFF)
+func (r *StandaloneMocks) EnableMockResponseDelay() *StandaloneMocks {
+	r.delay = true
+	return r
+}
+
+// Cassette records the outbound http interactions to the cassette file, or replays them as mocks if the cassette
+// was already recorded. The cassette is saved when the returned reset function is invoked
+func (r *StandaloneMocks) Cassette(cassette *Cassette) *StandaloneMocks {
+	r.cassette = cassette
+	return r
+}
+
+// PassthroughUnmatched forwards requests that do not match any mock to the real network if the request host is in the
+// given list of hosts, e.g. "localhost:8081" or "localhost". No requests are forwarded if no hosts are given
+func (r *StandaloneMocks) PassthroughUnmatched(hosts ...string) *StandaloneMocks {
+	r.passthrough = newPassthrough(hosts)
+	return r
+}
+
+// PassthroughAllUnmatched forwards requests to any host that do not match any mock to the real network
+func (r *StandaloneMocks) PassthroughAllUnmatched() *StandaloneMocks {
+	r.passthrough = &passthrough{all: true}
+	return r
+}
+
+// Scenarios sets the scenario state store used by the mocks, allowing the scenario states to be inspected and reset
+func (r *StandaloneMocks) Scenarios(scenarios *Scenarios) *StandaloneMocks {
+	r.scenarios = scenarios
+	return r
+}
+
+// Report records the interactions with the mocks and the result of Verify and formats them using the reporter, e.g.
+// apitest.SequenceDiagram(), when the reset function returned by End is invoked
+func (r *StandaloneMocks) Report(reporter ReportFormatter) *StandaloneMocks {
+	r.reporter = reporter
+	return r
+}
+
+// Recorder sets the recorder used by Report, e.g. to add custom events to the report
+func (r *StandaloneMocks) Recorder(recorder *Recorder) *StandaloneMocks {
+	r.recorder = recorder
+	return r
+}
+
+// Name sets the name of the report
+func (r *StandaloneMocks) Name(name string) *StandaloneMocks {
+	r.name = name
+	return r
+}
+
+// Meta adds custom meta data to the report
+func (r *StandaloneMocks) Meta(meta map[string]interface{}) *StandaloneMocks {
+	r.meta = meta
+	return r
+}
+
 // End finalises the mock, ready for use
 func (r *StandaloneMocks) End() func() {
+	mocks, recordingCassette := r.mocks, (*Cassette)(nil)
+	if r.cassette != nil {
+		if r.cassette.isRecording() {
+			recordingCassette = r.cassette
+		} else {
+			cassetteMocks, err := r.cassette.Mocks()
+			if err != nil {
+				panic(err)
+			}
+			mocks = append(append([]*Mock{}, r.mocks...), cassetteMocks...)
+		}
+	}
+
+	r.expanded = expandMocks(mocks)
+	for _, mock := range r.expanded {
+		mock.calls.reset()
+		mock.response.failFirst.reset()
+	}
+	r.started = time.Now()
+
 	transport := newTransport(
-		r.mocks,
+		r.expanded,
 		r.httpClient,
 		r.debug,
-		false,
-		nil,
+		r.delay,
+		[]Observe{r.observe},
 		nil,
 	)
-	resetFunc := func() { transport.Reset() }
+	transport.cassette = recordingCassette
+	transport.scenarios = r.scenarios
+	transport.passthrough = r.passthrough
+	if transport.scenarios == nil {
+		transport.scenarios = NewScenarios()
+	}
+	resetFunc := func() {
+		transport.Reset()
+		if recordingCassette != nil {
+			if err := recordingCassette.save(); err != nil {
+				panic(err)
+			}
+		}
+		if r.reporter != nil {
+			r.report()
+		}
+	}
 	transport.Hijack()
 	return resetFunc
 }
 
+// observe records the requests that did not match any mock and the interactions with the mocks for the report
+func (r *StandaloneMocks) observe(res *http.Response, req *http.Request, _ *APITest) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := mockMatchError(req); err != nil {
+		r.unmatched = append(r.unmatched, UnmatchedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Reason: err.Error(),
+		})
+	}
+	if r.reporter != nil {
+		r.recorded = append(r.recorded, newMockInteraction(res, req))
+	}
+}
+
+func (r *StandaloneMocks) report() {
+	r.mu.Lock()
+	interactions := r.recorded
+	r.recorded = nil
+	r.mu.Unlock()
+
+	recorder := r.recorder
+	if recorder == nil {
+		recorder = NewTestRecorder()
+	}
+	defer recorder.Reset()
+
+	title := r.name
+	if title == "" {
+		title = "Standalone mocks"
+	}
+	unmatched := r.UnmatchedRequests()
+	recorder.AddTitle(title).
+		AddSubTitle(fmt.Sprintf("%d outbound requests, %d did not match any mocks", len(interactions), len(unmatched)))
+	addMockInteractions(recorder, interactions)
+	sort.SliceStable(recorder.Events, func(i, j int) bool {
+		return recorder.Events[i].GetTime().Before(recorder.Events[j].GetTime())
+	})
+	if err := r.Verify(); err != nil {
+		recorder.AddAssertion(Assertion{Name: "Mocks", Passed: false, Message: err.Error()})
+	} else {
+		recorder.AddAssertion(Assertion{Name: "Mocks", Passed: true})
+	}
+
+	meta := map[string]interface{}{}
+	for k, v := range r.meta {
+		meta[k] = v
+	}
+	meta["path"] = ""
+	meta["method"] = ""
+	meta["name"] = r.name
+	meta["hash"] = createHash(meta)
+	meta["duration"] = time.Since(r.started).Nanoseconds()
+
+	recorder.AddMeta(meta)
+	r.reporter.Format(recorder)
+}
+
+// UnmatchedRequests returns the requests that did not match any mock
+func (r *StandaloneMocks) UnmatchedRequests() []UnmatchedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]UnmatchedRequest(nil), r.unmatched...)
+}
+
+// UnmatchedMocks returns the mocks that were not invoked the expected number of times
+func (r *StandaloneMocks) UnmatchedMocks() []UnmatchedMock {
+	return unmatchedMocks(r.expanded)
+}
+
+// Verify returns an error describing the requests that did not match any mock and the mocks that were not invoked
+// the expected number of times, or nil if there are none. It can be called before or after the reset function
+// returned by End is invoked
+func (r *StandaloneMocks) Verify() error {
+	var problems []string
+	for _, unmatched := range r.UnmatchedRequests() {
+		problems = append(problems, fmt.Sprintf("%s %s did not match any mocks\n\n%s", unmatched.Method, unmatched.URL, unmatched.Reason))
+	}
+	if unmatched := r.UnmatchedMocks(); len(unmatched) > 0 {
+		problems = append(problems, formatUnmatchedMocks(unmatched))
+	}
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "\n"))
+	}
+	return nil
+}
+
 // NewMock create a new mock, ready for configuration using the builder pattern
 func NewMock() *Mock {
 	mock := &Mock{
 		m:     &sync.Mutex{},
 		times: 1,
+		calls: &mockCalls{},
 	}
 	mock.request = &MockRequest{
 		mock:     mock,
@@ -394,6 +1118,26 @@ func (m *Mock) HttpClient(cli *http.Client) *Mock {
 	return m
 }
 
+// InScenario sets the name of the scenario used by InState and TransitionTo. Mocks without a scenario name share
+// the default scenario
+func (m *Mock) InScenario(name string) *Mock {
+	m.scenario = name
+	return m
+}
+
+// InState configures the mock to only match when the scenario is in the given state. Scenarios start in the
+// ScenarioStarted state
+func (m *Mock) InState(state string) *Mock {
+	m.requiredState = state
+	return m
+}
+
+// TransitionTo moves the scenario to the given state when the mock is matched
+func (m *Mock) TransitionTo(state string) *Mock {
+	m.newState = state
+	return m
+}
+
 // Get configures the mock to match http method GET
 func (m *Mock) Get(u string) *MockRequest {
 	m.parseUrl(u)
@@ -466,7 +1210,12 @@ func (m *Mock) parseUrl(u string) {
 	if err != nil {
 		panic(err)
 	}
+	template, _, err := parsePathTemplate(parsed.Path)
+	if err != nil {
+		panic(err)
+	}
 	m.request.url = parsed
+	m.request.template = template
 }
 
 // Method configures mock to match given http method
@@ -475,26 +1224,35 @@ func (m *Mock) Method(method string) *MockRequest {
 	return m.request
 }
 
-func matches(req *http.Request, mocks []*Mock) (*MockResponse, error) {
+func matches(req *http.Request, mocks []*Mock, scenarios *Scenarios) (*MockResponse, error) {
 	mockError := newUnmatchedMockError()
 	for mockNumber, mock := range mocks {
 		mock.m.Lock() // lock is for isUsed when matches is called concurrently by RoundTripper
-		if mock.isUsed && mock.anyTimesSet == false {
+		if mock.isUsed && !mock.unlimited() {
 			mock.m.Unlock()
 			continue
 		}
 
-		errs := mock.Matches(req)
+		errs, passed := mock.match(req)
+		// the scenarios are locked after matching so that custom matchers can read the scenario state
+		unlock := scenarios.lock()
+		if stateErr := scenarios.matchesState(mock); stateErr != nil {
+			errs = append(errs, stateErr)
+		}
 		if len(errs) == 0 {
 			mock.isUsed = true
+			scenarios.transition(mock)
+			unlock()
 			mock.m.Unlock()
 			return mock.response, nil
 		}
+		unlock()
 
-		mockError = mockError.addErrors(mockNumber+1, errs...)
+		mockError = mockError.addCandidate(mockNumber+1, mock, passed, errs...)
 		mock.m.Unlock()
 	}
 
+	mockError.request = newReceivedRequest(req)
 	return nil, mockError
 }
 
@@ -539,6 +1297,72 @@ func (r *MockRequest) JSON(v interface{}) *MockRequest {
 	return r
 }
 
+// JSONSubset configures the mock request to match a JSON body containing the given JSON. Fields of the received
+// body that are not in the given JSON are ignored. Use IgnoreArrayOrder to match arrays with elements in any order
+func (r *MockRequest) JSONSubset(v interface{}, options ...JSONSubsetOption) *MockRequest {
+	if s, ok := v.(string); ok && !json.Valid([]byte(s)) {
+		panic(fmt.Sprintf("invalid JSON subset %s", s))
+	}
+	expected, err := toJSONValue(v)
+	if err != nil {
+		panic(err)
+	}
+	ignoreArrayOrder := false
+	for _, option := range options {
+		if option == IgnoreArrayOrder {
+			ignoreArrayOrder = true
+		}
+	}
+
+	return r.AddMatcher(func(req *http.Request, _ *MockRequest) error {
+		actual, err := readJSONBody(req)
+		if err != nil {
+			return err
+		}
+		if err := jsonSubset("$", expected, actual, ignoreArrayOrder); err != nil {
+			return fmt.Errorf("received body did not match the JSON subset: %s", err)
+		}
+		return nil
+	})
+}
+
+// JSONPath configures the mock request to match a JSON body where the value selected by the JSONPath expression
+// satisfies the matcher, e.g. JSONPath("$.user.id", JSONValueEquals(12))
+func (r *MockRequest) JSONPath(expression string, matcher JSONValueMatcher) *MockRequest {
+	return r.AddMatcher(func(req *http.Request, _ *MockRequest) error {
+		actual, err := readJSONBody(req)
+		if err != nil {
+			return err
+		}
+		value, err := jsonPath(actual, expression)
+		if err != nil {
+			return fmt.Errorf("received body has no value at %s: %s", expression, err)
+		}
+		if err := matcher(value); err != nil {
+			return fmt.Errorf("received body value at %s did not match: %s", expression, err)
+		}
+		return nil
+	})
+}
+
+// readJSONBody decodes the JSON body of the request, replacing the body so that it can be read again
+func readJSONBody(req *http.Request) (interface{}, error) {
+	if req.Body == nil {
+		return nil, errors.New("expected a body but received none")
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var actual interface{}
+	if err := json.Unmarshal(body, &actual); err != nil {
+		return nil, fmt.Errorf("received body is not valid JSON: %s", err)
+	}
+	return actual, nil
+}
+
 // Header configures the mock request to match the given header
 func (r *MockRequest) Header(key, value string) *MockRequest {
 	normalizedKey := textproto.CanonicalMIMEHeaderKey(key)
@@ -717,6 +1541,31 @@ func (r *MockResponse) Bodyf(format string, args ...interface{}) *MockResponse {
 	return r.Body(fmt.Sprintf(format, args...))
 }
 
+// BodyTemplate sets the mock response body to the result of executing the Go text/template against the matched
+// request. See MockTemplateData for the available fields, e.g. {"id": "{{ index .PathSegments 1 }}", "name": "{{ .JSON.name }}"}
+func (r *MockResponse) BodyTemplate(tmpl string) *MockResponse {
+	t, err := template.New("mock response body").Parse(tmpl)
+	if err != nil {
+		panic(err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.bodyTemplate = t
+	return r
+}
+
+// RespondFunc uses the given function to build the response to the matched request. The timeout and delay
+// of the mock response are still applied
+func (r *MockResponse) RespondFunc(fn func(*http.Request) (*http.Response, error)) *MockResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.respondFunc = fn
+	return r
+}
+
 // BodyFromFile defines the mock response body from a file
 func (r *MockResponse) BodyFromFile(f string) *MockResponse {
 	r.mu.Lock()
@@ -755,6 +1604,7 @@ func (r *MockResponse) Status(statusCode int) *MockResponse {
 
 // FixedDelay will return the response after the given number of milliseconds.
 // APITest::EnableMockResponseDelay must be set for this to take effect.
+// If the request context ends during the delay the request fails with the context error.
 // If Timeout is set this has no effect.
 func (r *MockResponse) FixedDelay(delay int64) *MockResponse {
 	r.fixedDelayMillis = delay
@@ -777,6 +1627,30 @@ func (r *MockResponse) AnyTimes() *MockResponse {
 	return r
 }
 
+// AtLeast respond any number of times. The test fails if the mock is invoked fewer than the given number of times
+func (r *MockResponse) AtLeast(times int) *MockResponse {
+	r.mock.callRange = &callRange{min: times, max: -1}
+	return r
+}
+
+// AtMost respond any number of times. The test fails if the mock is invoked more than the given number of times
+func (r *MockResponse) AtMost(times int) *MockResponse {
+	r.mock.callRange = &callRange{min: 0, max: times}
+	return r
+}
+
+// Between respond any number of times. The test fails if the number of invocations of the mock is not between min and max inclusive
+func (r *MockResponse) Between(min, max int) *MockResponse {
+	r.mock.callRange = &callRange{min: min, max: max}
+	return r
+}
+
+// Never fails the test if the mock is invoked, e.g. to verify that a fallback is not called
+func (r *MockResponse) Never() *MockResponse {
+	r.mock.callRange = &callRange{min: 0, max: 0}
+	return r
+}
+
 // End finalise the response definition phase in order for the mock to be used
 func (r *MockResponse) End() *Mock {
 	return r.mock
@@ -792,6 +1666,7 @@ func (r *MockResponse) EndStandalone(other ...*Mock) func() {
 		nil,
 		nil,
 	)
+	transport.scenarios = NewScenarios()
 	resetFunc := func() { transport.Reset() }
 	transport.Hijack()
 	return resetFunc
@@ -807,7 +1682,17 @@ var pathMatcher Matcher = func(r *http.Request, spec *MockRequest) error {
 	if receivedPath == mockPath {
 		return nil
 	}
-	matched, err := regexp.MatchString(mockPath, receivedPath)
+	if spec.template != nil {
+		_, matched := spec.template.match(r.URL)
+		return errorOrNil(matched, func() string {
+			return fmt.Sprintf("received path %s did not match mock path %s", receivedPath, mockPath)
+		})
+	}
+	if mockPath == "" {
+		return nil
+	}
+	// mock paths are regular expressions which must match the whole received path
+	matched, err := regexp.MatchString("^(?:"+mockPath+")$", receivedPath)
 	return errorOrNil(matched && err == nil, func() string {
 		return fmt.Sprintf("received path %s did not match mock path %s", receivedPath, mockPath)
 	})
@@ -701,7 +701,7 @@ func TestMocks_AddMatcher(t *testing.T) {
 	tests := map[string]struct {
 		matcherResponse error
 		mockResponse    *MockResponse
-		matchErrors     error
+		matchErrors     map[int][]error
 	}{
 		"match": {
 			matcherResponse: nil,
@@ -714,9 +714,9 @@ func TestMocks_AddMatcher(t *testing.T) {
 		"no match": {
 			matcherResponse: errors.New("nope"),
 			mockResponse:    nil,
-			matchErrors: &unmatchedMockError{errors: map[int][]error{
+			matchErrors: map[int][]error{
 				1: {errors.New("nope")},
-			}},
+			},
 		},
 	}
 	for name, test := range tests {
@@ -734,9 +734,13 @@ func TestMocks_AddMatcher(t *testing.T) {
 				Status(http.StatusOK).
 				End()
 
-			mockResponse, matchErrors := matches(req, []*Mock{testMock})
+			mockResponse, matchErrors := matches(req, []*Mock{testMock}, nil)
 
-			assert.Equal(t, test.matchErrors, matchErrors)
+			if test.matchErrors == nil {
+				assert.NoError(t, matchErrors)
+			} else {
+				assert.Equal(t, test.matchErrors, matchErrors.(*unmatchedMockError).errors)
+			}
 			if test.mockResponse == nil {
 				assert.Equal(t, true, mockResponse == nil)
 			} else {
@@ -792,7 +796,7 @@ func TestMocks_Matches(t *testing.T) {
 		BodyFromFile("testdata/mock_response_body.json").
 		End()
 
-	mockResponse, matchErrors := matches(req, []*Mock{getUser, getPreferences})
+	mockResponse, matchErrors := matches(req, []*Mock{getUser, getPreferences}, nil)
 
 	assert.Equal(t, true, matchErrors == nil)
 	assert.Equal(t, true, mockResponse != nil)
@@ -816,10 +820,10 @@ func TestMocks_Matches_Errors(t *testing.T) {
 		Status(http.StatusOK).
 		End()
 
-	mockResponse, matchErrors := matches(req, []*Mock{testMock})
+	mockResponse, matchErrors := matches(req, []*Mock{testMock}, nil)
 
 	assert.Equal(t, true, mockResponse == nil)
-	assert.Equal(t, &unmatchedMockError{errors: map[int][]error{
+	assert.Equal(t, map[int][]error{
 		1: {
 			errors.New("received method GET did not match mock method POST"),
 			errors.New("not all of received headers map[] matched expected mock headers map[Headerkey:[headerVal headerVal]]"),
@@ -827,20 +831,20 @@ func TestMocks_Matches_Errors(t *testing.T) {
 			errors.New("expected query param queryKey2 not received"),
 			errors.New("expected a body but received none"),
 		},
-	}}, matchErrors)
+	}, matchErrors.(*unmatchedMockError).errors)
 }
 
 func TestMocks_Matches_NilIfNoMatch(t *testing.T) {
 	req := httptest.NewRequest(http.MethodGet, "/preferences/12345", nil)
 
-	mockResponse, matchErrors := matches(req, []*Mock{})
+	mockResponse, matchErrors := matches(req, []*Mock{}, nil)
 
 	if mockResponse != nil {
 		t.Fatal("Expected nil")
 	}
 
 	assert.Equal(t, true, matchErrors != nil)
-	assert.Equal(t, newUnmatchedMockError(), matchErrors)
+	assert.Equal(t, 0, len(matchErrors.(*unmatchedMockError).errors))
 }
 
 func TestMocks_UnmatchedMockErrorOrderedMockKeys(t *testing.T) {
@@ -862,14 +866,20 @@ func TestMocks_Matches_ErrorsMatchUnmatchedMocks(t *testing.T) {
 			NewMock().
 				Get("/preferences/123456").
 				RespondWith().
-				End()})
+				End()}, nil)
 
 	if mockResponse != nil {
 		t.Fatal("Expected nil")
 	}
 
 	assert.Equal(t, true, matchErrors != nil)
-	assert.Equal(t, "received request did not match any mocks\n\nMock 1 mismatches:\n• received path /preferences/12345 did not match mock path /preferences/123456\n\n",
+	assert.Equal(t, "received request did not match any mocks\n\n"+
+		"GET /preferences/12345\n\n"+
+		"Mock 1 GET /preferences/123456 passed 1 of 2 checks:\n"+
+		"   field   expected             received\n"+
+		"   method  GET                  GET\n"+
+		" ✗ path    /preferences/123456  /preferences/12345\n"+
+		"• received path /preferences/12345 did not match mock path /preferences/123456\n\n",
 		matchErrors.Error())
 }
 
@@ -1047,6 +1057,96 @@ func TestMocks_Response_Cookies(t *testing.T) {
 	}, response.Cookies())
 }
 
+func TestMocks_Response_BodyTemplate(t *testing.T) {
+	mockResponse := NewMock().
+		Post("/users/12/orders").
+		RespondWith().
+		BodyTemplate(`{"user": "{{ index .PathSegments 1 }}", "page": "{{ .Query.Get "page" }}", "trace": "{{ .Headers.Get "X-Trace" }}", "session": "{{ .Cookies.session }}", "item": "{{ .JSON.item.name }}"}`)
+	req := httptest.NewRequest(http.MethodPost, "http://example.com/users/12/orders?page=2", strings.NewReader(`{"item": {"name": "book"}}`))
+	req.Header.Set("X-Trace", "abc")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "s1"})
+
+	response, err := mockResponse.respond(req)
+
+	assert.NoError(t, err)
+	bytes, _ := ioutil.ReadAll(response.Body)
+	assert.Equal(t, `{"user": "12", "page": "2", "trace": "abc", "session": "s1", "item": "book"}`, string(bytes))
+	assert.Equal(t, "application/json", response.Header.Get("Content-Type"))
+	body, _ := ioutil.ReadAll(req.Body)
+	assert.Equal(t, `{"item": {"name": "book"}}`, string(body))
+}
+
+func TestMocks_Response_BodyTemplatePanicsIfInvalid(t *testing.T) {
+	defer func() {
+		assert.True(t, recover() != nil)
+	}()
+
+	NewMock().Get("/").RespondWith().BodyTemplate("{{ .Method")
+}
+
+func TestMocks_Response_RespondFunc(t *testing.T) {
+	mockResponse := NewMock().
+		Get("/").
+		RespondWith().
+		RespondFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusAccepted, Header: http.Header{"X-Method": {req.Method}}}, nil
+		})
+
+	response, err := mockResponse.respond(httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	assert.Equal(t, http.MethodGet, response.Header.Get("X-Method"))
+	assert.Equal(t, http.NoBody, response.Body)
+}
+
+func TestMocks_ApiTest_RespondFuncError(t *testing.T) {
+	getUser := NewMock().
+		Get("http://localhost:8080/user").
+		RespondWith().
+		RespondFunc(func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("connection reset")
+		}).
+		End()
+
+	New().
+		Mocks(getUser).
+		HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, err := http.Get("http://localhost:8080/user")
+			_, _ = w.Write([]byte(err.Error()))
+		}).
+		Get("/").
+		Expect(t).
+		Body(`Get "http://localhost:8080/user": connection reset`).
+		End()
+}
+
+func TestMocks_ApiTest_BodyTemplate(t *testing.T) {
+	getUser := NewMock().
+		Get("http://localhost:8080/users/[0-9]+").
+		RespondWith().
+		BodyTemplate(`{"id": {{ index .PathSegments 1 }}}`).
+		AnyTimes().
+		End()
+
+	New().
+		Mocks(getUser).
+		HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, id := range []string{"1", "2"} {
+				res, err := http.Get("http://localhost:8080/users/" + id)
+				if err != nil {
+					panic(err)
+				}
+				body, _ := ioutil.ReadAll(res.Body)
+				_, _ = w.Write(body)
+			}
+		}).
+		Get("/").
+		Expect(t).
+		Body(`{"id": 1}{"id": 2}`).
+		End()
+}
+
 func TestMocks_Standalone(t *testing.T) {
 	cli := http.Client{Timeout: 5}
 	defer NewMock().
@@ -1117,6 +1217,91 @@ func TestMocks_Standalone_WithCustomHTTPClient(t *testing.T) {
 	assert.Equal(t, http.StatusCreated, resp.StatusCode)
 }
 
+func TestMocks_Standalone_Report(t *testing.T) {
+	reporter := &RecorderCaptor{}
+	reset := NewStandaloneMocks(
+		NewMock().Get("http://localhost:8080/user").RespondWith().Status(http.StatusOK).Body("jon").End(),
+	).
+		Name("get user").
+		Meta(map[string]interface{}{"app": "users"}).
+		Report(reporter).
+		End()
+
+	res, err := http.Get("http://localhost:8080/user")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	_, err = http.Get("http://localhost:8080/posts")
+	assert.True(t, err != nil)
+	reset()
+
+	r := reporter.capturedRecorder
+	assert.Equal(t, "get user", r.Title)
+	assert.Equal(t, "2 outbound requests, 1 did not match any mocks", r.SubTitle)
+	assert.Equal(t, 1, len(r.Assertions))
+	assert.Equal(t, "Mocks", r.Assertions[0].Name)
+	assert.True(t, !r.Assertions[0].Passed)
+	assert.True(t, strings.HasPrefix(r.Assertions[0].Message, "GET http://localhost:8080/posts did not match any mocks"))
+	assert.Equal(t, 3, len(r.Events))
+	assert.Equal(t, "localhost:8080", r.Events[0].(HttpRequest).Target)
+	assert.Equal(t, SystemUnderTestDefaultName, r.Events[1].(HttpResponse).Target)
+	assert.True(t, strings.HasPrefix(r.Events[2].(HttpRequest).Error, "received request did not match any mocks"))
+	assert.Equal(t, "users", r.Meta["app"])
+	assert.Equal(t, "get user", r.Meta["name"])
+	assert.True(t, r.Meta["hash"] != nil)
+}
+
+func TestMocks_Standalone_ReportWithoutInteractions(t *testing.T) {
+	reporter := &RecorderCaptor{}
+	reset := NewStandaloneMocks(NewMock().Get("http://localhost:8080/user").RespondWith().End()).
+		Report(reporter).
+		End()
+
+	reset()
+
+	r := reporter.capturedRecorder
+	assert.Equal(t, "Standalone mocks", r.Title)
+	assert.Equal(t, "0 outbound requests, 0 did not match any mocks", r.SubTitle)
+	assert.Equal(t, 0, len(r.Events))
+	assert.Equal(t, []Assertion{{Name: "Mocks", Message: "mocks were not used:\n1. GET http://localhost:8080/user was not invoked"}}, r.Assertions)
+	model, err := newHTMLTemplateModel(&r)
+	assert.NoError(t, err)
+	assert.True(t, !model.HasStatus)
+}
+
+func TestMocks_Standalone_Verify(t *testing.T) {
+	standalone := NewStandaloneMocks(
+		NewMock().Get("http://localhost:8080/user").RespondWith().Body("jon").Times(2).End(),
+		NewMock().Get("http://localhost:8080/posts").RespondWith().Body("[]").End(),
+	)
+	reset := standalone.End()
+
+	_, err := http.Get("http://localhost:8080/user")
+	assert.NoError(t, err)
+	_, err = http.Post("http://localhost:8080/user", "text/plain", nil)
+	assert.True(t, err != nil)
+	reset()
+
+	unmatched := standalone.UnmatchedRequests()
+	assert.Equal(t, 1, len(unmatched))
+	assert.Equal(t, "http://localhost:8080/user", unmatched[0].URL)
+	assert.Equal(t, 2, len(standalone.UnmatchedMocks()))
+	err = standalone.Verify()
+	assert.True(t, strings.HasPrefix(err.Error(), "POST http://localhost:8080/user did not match any mocks"))
+	assert.True(t, strings.HasSuffix(err.Error(), "mocks were not used:\n"+
+		"1. GET http://localhost:8080/user expected 2 calls but received 1\n"+
+		"2. GET http://localhost:8080/posts was not invoked"))
+}
+
+func TestMocks_Standalone_VerifyPasses(t *testing.T) {
+	standalone := NewStandaloneMocks(NewMock().Get("http://localhost:8080/user").RespondWith().Body("jon").End())
+	defer standalone.End()()
+
+	_, err := http.Get("http://localhost:8080/user")
+
+	assert.NoError(t, err)
+	assert.NoError(t, standalone.Verify())
+}
+
 func TestMocks_WithHTTPTimeout(t *testing.T) {
 	httpClient := customCli
 	defer NewMock().
@@ -1322,6 +1507,147 @@ func TestMocks_ApiTest_SupportsMultipleMocks(t *testing.T) {
 		End()
 }
 
+func TestMocks_ApiTest_PassthroughUnmatched(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("real"))
+	}))
+	defer upstream.Close()
+	upstreamURL, _ := url.Parse(upstream.URL)
+	getUser := NewMock().
+		Get("http://localhost:8080").
+		RespondWith().
+		Body("mocked").
+		End()
+	reporter := &RecorderCaptor{}
+
+	New().
+		Report(reporter).
+		Mocks(getUser).
+		PassthroughUnmatched(upstreamURL.Host).
+		HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, u := range []string{"http://localhost:8080", upstream.URL, "http://example.com"} {
+				res, err := http.Get(u)
+				if err != nil {
+					_, _ = w.Write([]byte(" error"))
+					continue
+				}
+				body, _ := ioutil.ReadAll(res.Body)
+				_, _ = w.Write(body)
+			}
+		}).
+		Get("/").
+		Expect(t).
+		Body("mockedreal error").
+		End()
+
+	var passthroughResponse *http.Response
+	for _, event := range reporter.capturedRecorder.Events {
+		if res, ok := event.(HttpResponse); ok && res.Source == upstreamURL.Host {
+			passthroughResponse = res.Value
+		}
+	}
+	assert.True(t, passthroughResponse != nil)
+	assert.Equal(t, http.StatusOK, passthroughResponse.StatusCode)
+}
+
+func TestMocks_Passthrough_AllowsHosts(t *testing.T) {
+	tests := []struct {
+		hosts   []string
+		url     string
+		allowed bool
+	}{
+		{nil, "http://example.com/path", false},
+		{[]string{"localhost"}, "http://localhost:8081/path", true},
+		{[]string{"localhost:8081"}, "http://localhost:8081/path", true},
+		{[]string{"localhost:8082"}, "http://localhost:8081/path", false},
+		{[]string{"localhost"}, "http://example.com/path", false},
+	}
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, test.url, nil)
+			assert.Equal(t, test.allowed, newPassthrough(test.hosts).allows(req))
+		})
+	}
+
+	assert.Equal(t, false, (*passthrough)(nil).allows(httptest.NewRequest(http.MethodGet, "/", nil)))
+	assert.Equal(t, true, New().PassthroughAllUnmatched().passthrough.allows(httptest.NewRequest(http.MethodGet, "http://example.com/path", nil)))
+}
+
+func TestMocks_ApiTest_CapturesMockCalls(t *testing.T) {
+	createUser := NewMock().
+		Post("http://localhost:8080/user").
+		RespondWith().
+		Status(http.StatusCreated).
+		Body(`{"id": 1}`).
+		Times(2).
+		End()
+
+	result := New().
+		Mocks(createUser).
+		HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for _, name := range []string{"jon", "jan"} {
+				res, err := http.Post("http://localhost:8080/user", "application/json", strings.NewReader(`{"name": "`+name+`"}`))
+				if err != nil {
+					panic(err)
+				}
+				_, _ = ioutil.ReadAll(res.Body)
+			}
+		}).
+		Get("/").
+		Expect(t).
+		End()
+
+	calls := createUser.Calls()
+	assert.Equal(t, 2, len(calls))
+	body, _ := ioutil.ReadAll(calls[0].Request.Body)
+	assert.Equal(t, `{"name": "jon"}`, string(body))
+	body, _ = ioutil.ReadAll(calls[1].Request.Body)
+	assert.Equal(t, `{"name": "jan"}`, string(body))
+	assert.Equal(t, http.StatusCreated, calls[1].Response.StatusCode)
+	body, _ = ioutil.ReadAll(calls[1].Response.Body)
+	assert.Equal(t, `{"id": 1}`, string(body))
+	assert.True(t, !calls[0].Timestamp.After(calls[1].Timestamp))
+
+	body, _ = ioutil.ReadAll(createUser.Calls()[0].Request.Body)
+	assert.Equal(t, `{"name": "jon"}`, string(body))
+	assert.Equal(t, 2, len(result.MockCalls()))
+	assert.Equal(t, "/user", result.MockCalls()[0].Request.URL.Path)
+}
+
+func TestMocks_ApiTest_MockCallsAreResetForEachTest(t *testing.T) {
+	getUser := NewMock().
+		Get("http://localhost:8080").
+		RespondWith().
+		Body("1").
+		AnyTimes().
+		End()
+
+	for i := 0; i < 2; i++ {
+		New().
+			Mocks(getUser).
+			HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write(getUserData())
+			}).
+			Get("/").
+			Expect(t).
+			Body("1").
+			End()
+	}
+
+	assert.Equal(t, 1, len(getUser.Calls()))
+}
+
+func TestMocks_Timeout_RecordsCallWithoutResponse(t *testing.T) {
+	getUser := NewMock().Get("http://localhost:8080").RespondWith().Timeout().End()
+	defer NewStandaloneMocks(getUser).End()()
+
+	_, err := http.Get("http://localhost:8080")
+
+	assert.True(t, err != nil)
+	assert.Equal(t, 1, len(getUser.Calls()))
+	assert.Equal(t, true, getUser.Calls()[0].Response == nil)
+}
+
 func getUserData() []byte {
 	res, err := http.Get("http://localhost:8080")
 	if err != nil {
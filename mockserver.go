@@ -0,0 +1,296 @@
+package apitest
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MockServer serves mocks from a real http server so that they can be used by clients which are not running in the
+// same process as the test, such as subprocesses, browser frontends or clients built on custom transports.
+// The scheme and host of the mocks are ignored, so the mocks defined for a third party API can be served as is, unless
+// the server is a proxy or serves the hosts of the mocks using a certificate authority
+type MockServer struct {
+	// URL is the base URL of the server, e.g. http://127.0.0.1:1234
+	URL string
+
+	mocks        []*Mock
+	server       *httptest.Server
+	debug        bool
+	closed       chan struct{}
+	scenarios    *Scenarios
+	mu           sync.Mutex
+	unmatched    []UnmatchedRequest
+	proxy        bool
+	ca           *CertificateAuthority
+	tunnels      *tunnelListener
+	tunnelServer *http.Server
+
+	cassette         *Cassette
+	forwardTransport *http.Transport
+}
+
+// UnmatchedRequest is a request received by the MockServer that did not match any mock
+type UnmatchedRequest struct {
+	Method string
+	URL    string
+	Reason string
+}
+
+// NewMockServer starts a http server that responds to requests using the given mocks. Requests that do not match
+// a mock receive a 404 response. The server must be closed once the test is finished
+func NewMockServer(mocks ...*Mock) *MockServer {
+	s := newMockServer(mocks, false)
+	s.server.Start()
+	s.URL = s.server.URL
+	return s
+}
+
+// NewTLSMockServer starts a https server that responds to requests using the given mocks. Use Client to obtain a
+// http client that trusts the certificate of the server
+func NewTLSMockServer(mocks ...*Mock) *MockServer {
+	s := newMockServer(mocks, false)
+	s.server.StartTLS()
+	s.URL = s.server.URL
+	return s
+}
+
+// NewTLSMockServerWithCA starts a https server that serves the hosts of the mocks, e.g. api.example.com, using
+// certificates issued by the certificate authority, so that clients which verify certificates can be tested without
+// disabling verification. Unlike NewTLSMockServer the scheme and host of the mocks are matched. Use Client to obtain a
+// http client that sends requests for any host to the server, or DialContext to configure the transport of a client
+func NewTLSMockServerWithCA(ca *CertificateAuthority, mocks ...*Mock) *MockServer {
+	leaf, err := ca.Certificate("127.0.0.1")
+	if err != nil {
+		panic(err)
+	}
+	s := newMockServer(mocks, true)
+	s.ca = ca
+	s.server.TLS = ca.ServerConfig()
+	s.server.TLS.Certificates = []tls.Certificate{*leaf}
+	s.server.StartTLS()
+	s.URL = s.server.URL
+	return s
+}
+
+// newMockServer creates a mock server which is not started. The scheme and host of the mocks are ignored unless
+// matchHosts is true
+func newMockServer(mocks []*Mock, matchHosts bool) *MockServer {
+	s := &MockServer{closed: make(chan struct{}), scenarios: NewScenarios()}
+	for _, mock := range expandMocks(mocks) {
+		if mock.unlimited() {
+			mock = mock.copy()
+		}
+		if !matchHosts {
+			u := *mock.request.url
+			u.Scheme, u.Host = "", ""
+			mock.request.url = &u
+		}
+		s.mocks = append(s.mocks, mock)
+	}
+	s.server = httptest.NewUnstartedServer(s)
+	return s
+}
+
+// Debug switch on debugging mode
+func (s *MockServer) Debug() *MockServer {
+	s.debug = true
+	return s
+}
+
+// Client returns a http client configured to send requests to the server. For TLS servers the client trusts the
+// certificate of the server. For proxies the client sends all requests through the proxy and trusts the certificates
+// issued by the proxy. For servers using a certificate authority the client sends requests for any host to the server
+// and trusts the certificates issued by the authority
+func (s *MockServer) Client() *http.Client {
+	if s.proxy {
+		proxyURL, _ := url.Parse(s.URL)
+		return &http.Client{Transport: &http.Transport{
+			Proxy:           http.ProxyURL(proxyURL),
+			TLSClientConfig: &tls.Config{RootCAs: s.ca.CertPool()},
+		}}
+	}
+	if s.ca != nil {
+		return &http.Client{Transport: &http.Transport{
+			DialContext:     s.DialContext,
+			TLSClientConfig: &tls.Config{RootCAs: s.ca.CertPool()},
+		}}
+	}
+	return s.server.Client()
+}
+
+// DialContext connects to the server whatever the address, so that a client can send requests for the hosts of the
+// mocks to the server by using it as the DialContext of its transport
+func (s *MockServer) DialContext(ctx context.Context, network, _ string) (net.Conn, error) {
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, s.server.Listener.Addr().String())
+}
+
+// CA returns the certificate authority that issues the certificates of the server, or nil if there is none
+func (s *MockServer) CA() *CertificateAuthority {
+	return s.ca
+}
+
+// Scenarios returns the scenario states of the server, which can be inspected and reset between tests
+func (s *MockServer) Scenarios() *Scenarios {
+	return s.scenarios
+}
+
+// ServeHTTP responds to the request using the first matching mock
+func (s *MockServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if s.proxy && req.Method == http.MethodConnect {
+		s.serveConnect(w, req)
+		return
+	}
+
+	matchedResponse, matchErrors := matches(req, s.mocks, s.scenarios)
+	if matchErrors != nil && s.cassette != nil {
+		s.forward(w, req)
+		return
+	}
+	if matchErrors != nil {
+		if s.debug {
+			fmt.Printf("failed to match mocks. Errors: %s\n", matchErrors)
+		}
+		s.mu.Lock()
+		s.unmatched = append(s.unmatched, UnmatchedRequest{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Reason: matchErrors.Error(),
+		})
+		s.mu.Unlock()
+		http.Error(w, matchErrors.Error(), http.StatusNotFound)
+		return
+	}
+
+	if matchedResponse.timeout {
+		matchedResponse.mock.calls.add(newMockCall(req, nil))
+		select {
+		case <-req.Context().Done():
+		case <-s.closed:
+		}
+		return
+	}
+
+	if err := sleepContext(req.Context(), matchedResponse.delay()); err != nil {
+		return
+	}
+
+	fault := matchedResponse.nextFault()
+	res, err := matchedResponse.respond(req)
+	if err == nil && res.StatusCode == 0 {
+		res.StatusCode = http.StatusOK
+	}
+	matchedResponse.mock.calls.add(newMockCall(req, res))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if s.debug {
+		debugMock(res, req)
+	}
+	body, _ := ioutil.ReadAll(res.Body)
+	_ = res.Body.Close()
+
+	if fault.serveFault(w, res, string(body)) {
+		return
+	}
+
+	for key, values := range res.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(res.StatusCode)
+	if matchedResponse.dripFeed != nil {
+		serveDripFeed(w, req, body, *matchedResponse.dripFeed)
+		return
+	}
+	_, _ = w.Write(body)
+}
+
+// serveDripFeed writes the body in chunks, flushing each chunk to the client
+func serveDripFeed(w http.ResponseWriter, req *http.Request, body []byte, feed dripFeed) {
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+	for len(body) > 0 {
+		select {
+		case <-time.After(feed.interval):
+		case <-req.Context().Done():
+			return
+		}
+		n := feed.chunkSize
+		if n > len(body) {
+			n = len(body)
+		}
+		if _, err := w.Write(body[:n]); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+		body = body[n:]
+	}
+}
+
+// UnmatchedRequests returns the requests received by the server that did not match any mock
+func (s *MockServer) UnmatchedRequests() []UnmatchedRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]UnmatchedRequest(nil), s.unmatched...)
+}
+
+// UnmatchedMocks returns the mocks that did not receive a matching request
+func (s *MockServer) UnmatchedMocks() []UnmatchedMock {
+	return unmatchedMocks(s.mocks)
+}
+
+// Close shuts down the server and verifies the interactions. An error is returned if the server received requests
+// that did not match any mock or if mocks with an expected number of invocations were not invoked. A proxy recording
+// a cassette saves the cassette
+func (s *MockServer) Close() error {
+	close(s.closed)
+	s.server.Close()
+	if s.tunnelServer != nil {
+		_ = s.tunnelServer.Close()
+	}
+
+	var problems []string
+	if s.cassette != nil {
+		s.forwardTransport.CloseIdleConnections()
+		if err := s.cassette.save(); err != nil {
+			problems = append(problems, fmt.Sprintf("failed to save cassette %s: %s", s.cassette.path, err))
+		}
+	}
+	for _, unmatched := range s.UnmatchedRequests() {
+		problems = append(problems, fmt.Sprintf("%s %s did not match any mocks\n\n%s", unmatched.Method, unmatched.URL, unmatched.Reason))
+	}
+	reported := map[*mockCalls]bool{}
+	for _, mock := range s.mocks {
+		if !mock.unlimited() && !mock.isUsed && mock.timesSet && !reported[mock.calls] {
+			reported[mock.calls] = true
+			problems = append(problems, fmt.Sprintf("mock %s %s expected %d calls but received %d\n\n%s",
+				mock.request.method, mock.request.url, expectedCalls(mock, s.mocks), mock.calls.count(), formatMockCalls(mock.calls.list())))
+		}
+		if mock.callRange != nil && !mock.callRange.contains(mock.calls.count()) {
+			problems = append(problems, fmt.Sprintf("mock %s %s expected %s calls but received %d\n\n%s",
+				mock.request.method, mock.request.url, mock.callRange, mock.calls.count(), formatMockCalls(mock.calls.list())))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(strings.Join(problems, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package apitest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMockServer_ServesMocks(t *testing.T) {
+	server := NewMockServer(
+		NewMock().
+			Get("http://example.com/user").
+			Query("id", "1").
+			RespondWith().
+			Status(http.StatusOK).
+			Header("X-Request-Id", "abc").
+			Body(`{"name": "jon"}`).
+			End(),
+	)
+
+	res, err := http.Get(server.URL + "/user?id=1")
+
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, `{"name": "jon"}`, string(body))
+	assert.Equal(t, "abc", res.Header.Get("X-Request-Id"))
+	assert.Equal(t, "application/json", res.Header.Get("Content-Type"))
+	assert.NoError(t, server.Close())
+}
+
+func TestMockServer_ServesTLS(t *testing.T) {
+	server := NewTLSMockServer(NewMock().Get("/user").RespondWith().Body("jon").End())
+	assert.True(t, strings.HasPrefix(server.URL, "https://"))
+
+	res, err := server.Client().Get(server.URL + "/user")
+
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, "jon", string(body))
+	assert.NoError(t, server.Close())
+}
+
+func TestMockServer_ReportsUnmatchedRequests(t *testing.T) {
+	server := NewMockServer(NewMock().Get("/user").RespondWith().Body("jon").End())
+
+	res, err := http.Post(server.URL+"/user", "text/plain", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+	unmatched := server.UnmatchedRequests()
+	assert.Equal(t, 1, len(unmatched))
+	assert.Equal(t, http.MethodPost, unmatched[0].Method)
+	assert.Equal(t, "/user", unmatched[0].URL)
+	assert.True(t, strings.Contains(unmatched[0].Reason, "received method POST did not match mock method GET"))
+	assert.Equal(t, 1, len(server.UnmatchedMocks()))
+	err = server.Close()
+	assert.True(t, strings.HasPrefix(err.Error(), "POST /user did not match any mocks"))
+}
+
+func TestMockServer_CloseVerifiesMockInvocations(t *testing.T) {
+	server := NewMockServer(NewMock().Get("/user").RespondWith().Body("jon").Times(2).End())
+
+	_, err := http.Get(server.URL + "/user")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "mock GET /user expected 2 calls but received 1\n\nmock calls received:\n1. GET /user -> 200", server.Close().Error())
+}
+
+func TestMockServer_AnyTimes(t *testing.T) {
+	mock := NewMock().Get("/user").RespondWith().Body("jon").AnyTimes().End()
+	server := NewMockServer(mock)
+
+	for i := 0; i < 3; i++ {
+		res, err := http.Get(server.URL + "/user")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+	}
+
+	assert.NoError(t, server.Close())
+	assert.Equal(t, false, mock.isUsed)
+}
+
+func TestMockServer_CloseVerifiesCallRange(t *testing.T) {
+	server := NewMockServer(NewMock().Get("/user").RespondWith().Body("jon").AtLeast(2).End())
+
+	_, err := http.Get(server.URL + "/user")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "mock GET /user expected at least 2 calls but received 1\n\nmock calls received:\n1. GET /user -> 200", server.Close().Error())
+}
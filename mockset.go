@@ -0,0 +1,148 @@
+package apitest
+
+import (
+	"fmt"
+	"net/textproto"
+	"sync"
+)
+
+// MockSet is a library of named mocks that can be shared between tests, e.g. the fakes of a downstream service.
+// The mocks in the set are templates which are never invoked, instead Get and Mocks return fresh copies so that
+// state does not leak between tests
+type MockSet struct {
+	mu    sync.RWMutex
+	names []string
+	mocks map[string]*Mock
+}
+
+// NewMockSet creates an empty mock set
+func NewMockSet() *MockSet {
+	return &MockSet{mocks: map[string]*Mock{}}
+}
+
+// Add adds a copy of the mock to the set under the given name. Changes made to the mock after it is added do not
+// affect the set. Add panics if the name is already used
+func (s *MockSet) Add(name string, mock *Mock) *MockSet {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.mocks[name]; ok {
+		panic(fmt.Sprintf("mock %s is already defined in the mock set", name))
+	}
+	s.names = append(s.names, name)
+	s.mocks[name] = mock.Copy()
+	return s
+}
+
+// Get returns a copy of the named mock. Get panics if the mock is not defined
+func (s *MockSet) Get(name string) *Mock {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	mock, ok := s.mocks[name]
+	if !ok {
+		panic(fmt.Sprintf("mock %s is not defined in the mock set", name))
+	}
+	return mock.Copy()
+}
+
+// Mocks returns copies of the named mocks, or of all the mocks in the order they were added if no names are given
+func (s *MockSet) Mocks(names ...string) []*Mock {
+	if len(names) == 0 {
+		s.mu.RLock()
+		names = append([]string(nil), s.names...)
+		s.mu.RUnlock()
+	}
+	mocks := make([]*Mock, 0, len(names))
+	for _, name := range names {
+		mocks = append(mocks, s.Get(name))
+	}
+	return mocks
+}
+
+// Copy returns an independent copy of the mock which has not been invoked, so that a mock can be defined once and
+// used by several tests, or changed without affecting the original. Random faults and delays of the copy restart the
+// sequence of their seed
+func (m *Mock) Copy() *Mock {
+	c := m.copy()
+	c.isUsed = false
+	c.calls = &mockCalls{}
+	if m.callRange != nil {
+		callRange := *m.callRange
+		c.callRange = &callRange
+	}
+
+	c.request.mock = c
+	if m.request.url != nil {
+		u := *m.request.url
+		c.request.url = &u
+	}
+	c.request.headers = copyValues(m.request.headers)
+	c.request.formData = copyValues(m.request.formData)
+	c.request.query = copyValues(m.request.query)
+	c.request.headerPresent = append([]string(nil), m.request.headerPresent...)
+	c.request.headerNotPresent = append([]string(nil), m.request.headerNotPresent...)
+	c.request.formDataPresent = append([]string(nil), m.request.formDataPresent...)
+	c.request.formDataNotPresent = append([]string(nil), m.request.formDataNotPresent...)
+	c.request.queryPresent = append([]string(nil), m.request.queryPresent...)
+	c.request.queryNotPresent = append([]string(nil), m.request.queryNotPresent...)
+	c.request.cookie = append([]Cookie(nil), m.request.cookie...)
+	c.request.cookiePresent = append([]string(nil), m.request.cookiePresent...)
+	c.request.cookieNotPresent = append([]string(nil), m.request.cookieNotPresent...)
+	c.request.matchers = append([]Matcher(nil), m.request.matchers...)
+
+	c.response.randomFault = m.response.randomFault.copy()
+	c.response.delayDistribution = m.response.delayDistribution.copy()
+	if m.response.dripFeed != nil {
+		feed := *m.response.dripFeed
+		c.response.dripFeed = &feed
+	}
+	if m.response.failFirst != nil {
+		c.response.failFirst = &failFirst{
+			calls:      m.response.failFirst.calls,
+			statusCode: m.response.failFirst.statusCode,
+			retryAfter: m.response.failFirst.retryAfter,
+		}
+	}
+	return c
+}
+
+// WithStatus returns a copy of the mock that responds with the given status code
+func (m *Mock) WithStatus(statusCode int) *Mock {
+	c := m.Copy()
+	c.response.Status(statusCode)
+	return c
+}
+
+// WithBody returns a copy of the mock that responds with the given body, replacing a body template
+func (m *Mock) WithBody(body string) *Mock {
+	c := m.Copy()
+	c.response.bodyTemplate = nil
+	c.response.Body(body)
+	return c
+}
+
+// WithHeader returns a copy of the mock that responds with the given header, replacing any values of the header
+func (m *Mock) WithHeader(key, value string) *Mock {
+	c := m.Copy()
+	c.response.headers[textproto.CanonicalMIMEHeaderKey(key)] = []string{value}
+	return c
+}
+
+// WithTimes returns a copy of the mock that responds the given number of times
+func (m *Mock) WithTimes(times int) *Mock {
+	c := m.Copy()
+	c.response.Times(times)
+	return c
+}
+
+func copyValues(values map[string][]string) map[string][]string {
+	if values == nil {
+		return nil
+	}
+	c := make(map[string][]string, len(values))
+	for key, v := range values {
+		c[key] = append([]string(nil), v...)
+	}
+	return c
+}
@@ -0,0 +1,103 @@
+package apitest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMockSet_ReturnsCopies(t *testing.T) {
+	getUser := NewMock().Get("http://localhost:8080/user").Header("Authorization", "Bearer abc").RespondWith().Body("jon").End()
+	set := NewMockSet().Add("getUser", getUser)
+
+	first := set.Get("getUser")
+	first.request.Header("X-Tenant", "a")
+	first.isUsed = true
+	first.calls.add(MockCall{})
+	second := set.Get("getUser")
+
+	assert.True(t, first != second)
+	assert.True(t, !second.isUsed)
+	assert.Equal(t, 0, len(second.Calls()))
+	assert.Equal(t, map[string][]string{"Authorization": {"Bearer abc"}}, second.request.headers)
+	assert.True(t, second.request.mock == second)
+	assert.True(t, second.response.mock == second)
+
+	getUser.response.Status(http.StatusTeapot)
+	assert.Equal(t, 0, set.Get("getUser").response.statusCode)
+}
+
+func TestMockSet_Mocks(t *testing.T) {
+	set := NewMockSet().
+		Add("getUser", NewMock().Get("/user").RespondWith().End()).
+		Add("getPosts", NewMock().Get("/posts").RespondWith().End())
+
+	assert.Equal(t, "/user", set.Mocks()[0].request.url.Path)
+	assert.Equal(t, "/posts", set.Mocks()[1].request.url.Path)
+	mocks := set.Mocks("getPosts")
+	assert.Equal(t, 1, len(mocks))
+	assert.Equal(t, "/posts", mocks[0].request.url.Path)
+}
+
+func TestMockSet_PanicsIfMockNotDefined(t *testing.T) {
+	defer func() {
+		assert.Equal(t, "mock getUser is not defined in the mock set", recover())
+	}()
+
+	NewMockSet().Get("getUser")
+}
+
+func TestMockSet_PanicsIfNameAlreadyUsed(t *testing.T) {
+	defer func() {
+		assert.Equal(t, "mock getUser is already defined in the mock set", recover())
+	}()
+
+	mock := NewMock().Get("/user").RespondWith().End()
+	NewMockSet().Add("getUser", mock).Add("getUser", mock)
+}
+
+func TestMockSet_Overrides(t *testing.T) {
+	getUser := NewMock().
+		Get("/user").
+		RespondWith().
+		Status(http.StatusOK).
+		Header("Content-Type", "application/json").
+		BodyTemplate(`{"path": "{{ .Path }}"}`).
+		End()
+
+	failing := getUser.WithStatus(http.StatusInternalServerError).WithBody("oops").WithHeader("content-type", "text/plain").WithTimes(2)
+
+	res, err := failing.response.respond(httptest.NewRequest(http.MethodGet, "/user", nil))
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	assert.Equal(t, "oops", string(body))
+	assert.Equal(t, "text/plain", res.Header.Get("Content-Type"))
+	assert.Equal(t, 2, failing.times)
+	assert.Equal(t, http.StatusOK, getUser.response.statusCode)
+	assert.Equal(t, 1, getUser.times)
+	assert.Equal(t, []string{"application/json"}, getUser.response.headers["Content-Type"])
+}
+
+func TestMockSet_CopiesRepeatRandomSequences(t *testing.T) {
+	flaky := NewMock().
+		Get("/user").
+		RespondWith().
+		RandomFault(42).
+		UniformDelay(0, time.Second, 7).
+		DripFeed(1, time.Millisecond).
+		AnyTimes().
+		End()
+	set := NewMockSet().Add("flaky", flaky)
+
+	first, second := set.Get("flaky"), set.Get("flaky")
+
+	assert.True(t, first.response.randomFault != second.response.randomFault)
+	assert.True(t, first.response.dripFeed != second.response.dripFeed)
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, first.response.nextFault(), second.response.nextFault())
+		assert.Equal(t, first.response.delayDistribution.next(), second.response.delayDistribution.next())
+	}
+}
@@ -0,0 +1,69 @@
+package apitest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// DenyNetworkByDefault enables DenyNetwork for every API test, e.g. set it in TestMain
+var DenyNetworkByDefault bool
+
+// denyNetworkKey marks the context of the inbound request of an API test that denies network access
+type denyNetworkKey struct{}
+
+// deniedRequests records the outbound requests that were not mocked in a test that denies network access
+type deniedRequests struct {
+	mu       sync.Mutex
+	requests []string
+}
+
+func (d *deniedRequests) add(req *http.Request) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.requests = append(d.requests, fmt.Sprintf("%s %s", req.Method, req.URL))
+}
+
+func (d *deniedRequests) String() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if len(d.requests) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("network access denied, outbound requests did not match any mocks:")
+	for i, request := range d.requests {
+		b.WriteString(fmt.Sprintf("\n%d. %s", i+1, request))
+	}
+	return b.String()
+}
+
+// DialContext dials the address using a net.Dialer unless the context is derived from the inbound request of an API
+// test using DenyNetwork, in which case the connection fails. Loopback addresses are always allowed. Use it as the
+// DialContext of the http.Transport of clients that build their own transport and send outbound requests with the
+// context of the inbound request, so that they are covered by DenyNetwork
+func DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	if deniesDial(ctx, address) {
+		return nil, &net.OpError{Op: "dial", Net: network, Err: fmt.Errorf("network access to %s denied by apitest", address)}
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, address)
+}
+
+func deniesDial(ctx context.Context, address string) bool {
+	if denied, _ := ctx.Value(denyNetworkKey{}).(bool); !denied {
+		return false
+	}
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		host = address
+	}
+	if host == "localhost" {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip == nil || !ip.IsLoopback()
+}
@@ -0,0 +1,17 @@
+package apitest
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNetwork_DeniesDialOnlyInDeniedContext(t *testing.T) {
+	denied := context.WithValue(context.Background(), denyNetworkKey{}, true)
+
+	assert.True(t, deniesDial(denied, "example.com:443"))
+	assert.True(t, deniesDial(denied, "192.0.2.1:80"))
+	assert.True(t, !deniesDial(denied, "localhost:8080"))
+	assert.True(t, !deniesDial(denied, "127.0.0.1:8080"))
+	assert.True(t, !deniesDial(denied, "[::1]:8080"))
+	assert.True(t, !deniesDial(context.Background(), "example.com:443"))
+}
@@ -0,0 +1,115 @@
+package apitest
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var pathWildcardRegexp = regexp.MustCompile(`^\{([A-Za-z_][A-Za-z0-9_]*(\.\.\.)?|\$)\}$`)
+
+// pathTemplate is a mock path using the http.ServeMux pattern syntax, e.g. /users/{id}, /files/{path...} or /users/{$}.
+// Unlike regular expression paths the template must match the whole received path
+type pathTemplate struct {
+	segments []string
+}
+
+// parsePathTemplate returns the template of the mock path, or false if the path contains no wildcards
+func parsePathTemplate(path string) (*pathTemplate, bool, error) {
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	isTemplate := false
+	names := map[string]bool{}
+	for i, segment := range segments {
+		match := pathWildcardRegexp.FindStringSubmatch(segment)
+		if match == nil {
+			continue
+		}
+		isTemplate = true
+
+		name := strings.TrimSuffix(match[1], "...")
+		if (name == "$" || name != match[1]) && i != len(segments)-1 {
+			return nil, true, fmt.Errorf("invalid path template %s: {%s} must be the last segment", path, match[1])
+		}
+		if names[name] {
+			return nil, true, fmt.Errorf("invalid path template %s: duplicate wildcard name %s", path, name)
+		}
+		names[name] = true
+	}
+	if !isTemplate {
+		return nil, false, nil
+	}
+	return &pathTemplate{segments: segments}, true, nil
+}
+
+// match returns the values captured by the wildcards of the template, or false if the path does not match
+func (t *pathTemplate) match(u *url.URL) (map[string]string, bool) {
+	received := strings.Split(strings.TrimPrefix(u.EscapedPath(), "/"), "/")
+	values := map[string]string{}
+	for i, segment := range t.segments {
+		last := i == len(t.segments)-1
+		wildcard := pathWildcardRegexp.FindStringSubmatch(segment)
+
+		switch {
+		case wildcard != nil && wildcard[1] == "$":
+			return values, len(received) == i+1 && received[i] == ""
+		case wildcard != nil && strings.HasSuffix(wildcard[1], "..."):
+			if i >= len(received) {
+				return nil, false
+			}
+			value, err := url.PathUnescape(strings.Join(received[i:], "/"))
+			if err != nil {
+				return nil, false
+			}
+			values[strings.TrimSuffix(wildcard[1], "...")] = value
+			return values, true
+		case last && segment == "":
+			// a trailing slash matches any path with the template as a prefix, as with http.ServeMux
+			return values, i < len(received)
+		}
+
+		if i >= len(received) {
+			return nil, false
+		}
+		value, err := url.PathUnescape(received[i])
+		if err != nil {
+			return nil, false
+		}
+		if wildcard != nil {
+			if value == "" {
+				return nil, false
+			}
+			values[wildcard[1]] = value
+			continue
+		}
+		if literal, err := url.PathUnescape(segment); err != nil || literal != value {
+			return nil, false
+		}
+	}
+	return values, len(received) == len(t.segments)
+}
+
+// pathValues returns the values captured by the path template of the mock request for the given request
+func (r *MockRequest) pathValues(req *http.Request) map[string]string {
+	if r.template == nil {
+		return nil
+	}
+	values, _ := r.template.match(req.URL)
+	return values
+}
+
+// withPathValues returns a shallow copy of the request with the values captured by the path template of the mock
+// request set, so that they are available from http.Request.PathValue
+func (r *MockRequest) withPathValues(req *http.Request) *http.Request {
+	values := r.pathValues(req)
+	if len(values) == 0 {
+		return req
+	}
+	withValues := new(http.Request)
+	*withValues = *req
+	for name, value := range values {
+		withValues.SetPathValue(name, value)
+	}
+	return withValues
+}
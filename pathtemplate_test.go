@@ -0,0 +1,176 @@
+package apitest
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPathTemplate_Matches(t *testing.T) {
+	tests := []struct {
+		template string
+		path     string
+		matched  bool
+		values   map[string]string
+	}{
+		{"/users/{id}", "/users/1", true, map[string]string{"id": "1"}},
+		{"/users/{id}", "/users/10", true, map[string]string{"id": "10"}},
+		{"/users/{id}", "/users/1/posts", false, nil},
+		{"/users/{id}", "/users/", false, nil},
+		{"/users/{id}", "/accounts/1", false, nil},
+		{"/users/{id}/posts/{post}", "/users/1/posts/2", true, map[string]string{"id": "1", "post": "2"}},
+		{"/users/{name}", "/users/jon%20smith", true, map[string]string{"name": "jon smith"}},
+		{"/files/{path...}", "/files/a/b/c.txt", true, map[string]string{"path": "a/b/c.txt"}},
+		{"/files/{path...}", "/files/", true, map[string]string{"path": ""}},
+		{"/users/{id}/", "/users/1/posts", true, map[string]string{"id": "1"}},
+		{"/users/{id}/{$}", "/users/1/", true, map[string]string{"id": "1"}},
+		{"/users/{id}/{$}", "/users/1/posts", false, nil},
+	}
+	for _, test := range tests {
+		t.Run(test.template+" "+test.path, func(t *testing.T) {
+			template, ok, err := parsePathTemplate(test.template)
+			assert.True(t, ok)
+			assert.NoError(t, err)
+
+			values, matched := template.match(httptest.NewRequest(http.MethodGet, test.path, nil).URL)
+
+			assert.Equal(t, test.matched, matched)
+			if test.matched {
+				assert.Equal(t, test.values, values)
+			}
+		})
+	}
+}
+
+func TestPathTemplate_NotATemplate(t *testing.T) {
+	for _, path := range []string{"/users/1", "/users/[0-9]+", "/users/[0-9]{3}", "/users/{1}"} {
+		_, ok, err := parsePathTemplate(path)
+
+		assert.True(t, !ok)
+		assert.NoError(t, err)
+	}
+}
+
+func TestPathTemplate_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"/files/{path...}/a": "invalid path template /files/{path...}/a: {path...} must be the last segment",
+		"/users/{$}/a":       "invalid path template /users/{$}/a: {$} must be the last segment",
+		"/users/{id}/{id}":   "invalid path template /users/{id}/{id}: duplicate wildcard name id",
+	}
+	for path, expected := range tests {
+		t.Run(path, func(t *testing.T) {
+			defer func() {
+				assert.Equal(t, expected, recover().(error).Error())
+			}()
+
+			NewMock().Get(path)
+		})
+	}
+}
+
+func TestPathTemplate_MockPathIsAnchored(t *testing.T) {
+	getUser := NewMock().Get("/users/{id}").RespondWith().End()
+
+	assert.Equal(t, 0, len(getUser.Matches(httptest.NewRequest(http.MethodGet, "/users/1", nil))))
+	errs := getUser.Matches(httptest.NewRequest(http.MethodGet, "/api/users/1/posts", nil))
+	assert.Equal(t, 1, len(errs))
+	assert.Equal(t, "received path /api/users/1/posts did not match mock path /users/{id}", errs[0].Error())
+}
+
+func TestPathTemplate_LiteralMockPathIsAnchored(t *testing.T) {
+	getUser := NewMock().Get("/users/1").RespondWith().End()
+
+	assert.True(t, getUser.request.template == nil)
+	assert.Equal(t, 0, len(getUser.Matches(httptest.NewRequest(http.MethodGet, "/users/1", nil))))
+	for _, path := range []string{"/users/10", "/api/users/1"} {
+		errs := getUser.Matches(httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, 1, len(errs))
+		assert.Equal(t, "received path "+path+" did not match mock path /users/1", errs[0].Error())
+	}
+
+	getUsers := NewMock().Get("/users/[0-9]+").RespondWith().End()
+	assert.Equal(t, 0, len(getUsers.Matches(httptest.NewRequest(http.MethodGet, "/users/10", nil))))
+	assert.Equal(t, 1, len(getUsers.Matches(httptest.NewRequest(http.MethodGet, "/users/10/posts", nil))))
+}
+
+func TestPathTemplate_DottedMockPathIsAnchored(t *testing.T) {
+	tests := []struct {
+		mockPath string
+		path     string
+		matches  bool
+	}{
+		{"/files/report.pdf", "/files/report.pdf", true},
+		{"/files/report.pdf", "/files/report.pdf.bak", false},
+		{"/v1.0/users/1", "/v1.0/users/1", true},
+		{"/v1.0/users/1", "/v1.0/users/10", false},
+		{"/v1.0/users/1", "/api/v1.0/users/1", false},
+	}
+	for _, test := range tests {
+		t.Run(test.mockPath+" "+test.path, func(t *testing.T) {
+			mock := NewMock().Get("http://example.com" + test.mockPath).RespondWith().End()
+
+			errs := mock.Matches(httptest.NewRequest(http.MethodGet, "http://example.com"+test.path, nil))
+
+			assert.Equal(t, test.matches, len(errs) == 0)
+		})
+	}
+}
+
+func TestPathTemplate_ParsedWhenMockIsBuilt(t *testing.T) {
+	getUser := NewMock().Get("/users/{id}").RespondWith().End()
+
+	assert.Equal(t, []string{"users", "{id}"}, getUser.request.template.segments)
+	assert.True(t, getUser.Copy().request.template == getUser.request.template)
+}
+
+func TestPathTemplate_ValuesAvailableToMatchers(t *testing.T) {
+	getUser := NewMock().
+		Post("/users/{id}").
+		Body(`{"name": "jon"}`).
+		AddMatcher(func(req *http.Request, _ *MockRequest) error {
+			if req.PathValue("id") != "1" {
+				return errors.New("unexpected id " + req.PathValue("id"))
+			}
+			return nil
+		}).
+		RespondWith().
+		End()
+	req := httptest.NewRequest(http.MethodPost, "/users/1", strings.NewReader(`{"name": "jon"}`))
+
+	assert.Equal(t, 0, len(getUser.Matches(req)))
+	assert.Equal(t, "", req.PathValue("id"))
+	body, err := ioutil.ReadAll(req.Body)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"name": "jon"}`, string(body))
+	assert.Equal(t, 1, len(getUser.Matches(httptest.NewRequest(http.MethodPost, "/users/2", strings.NewReader(`{"name": "jon"}`)))))
+}
+
+func TestPathTemplate_ValuesAvailableToResponses(t *testing.T) {
+	getUser := NewMock().
+		Get("http://localhost:8080/users/{id}").
+		RespondWith().
+		BodyTemplate(`{"id": "{{ .PathValues.id }}"}`).
+		End()
+	getPost := NewMock().
+		Get("http://localhost:8080/posts/{id}").
+		RespondWith().
+		RespondFunc(func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(req.PathValue("id")))}, nil
+		}).
+		End()
+	reset := NewStandaloneMocks(getUser, getPost).End()
+	defer reset()
+
+	res, err := http.Get("http://localhost:8080/users/12")
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, `{"id": "12"}`, string(body))
+
+	res, err = http.Get("http://localhost:8080/posts/34")
+	assert.NoError(t, err)
+	body, _ = ioutil.ReadAll(res.Body)
+	assert.Equal(t, "34", string(body))
+}
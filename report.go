@@ -20,10 +20,21 @@ type (
 
 	// Recorder represents all of the report data
 	Recorder struct {
-		Title    string
-		SubTitle string
-		Meta     map[string]interface{}
-		Events   []Event
+		Title      string
+		SubTitle   string
+		Meta       map[string]interface{}
+		Events     []Event
+		Assertions []Assertion
+	}
+
+	// Assertion represents the outcome of an assertion performed by the Verifier
+	Assertion struct {
+		Name     string
+		Passed   bool
+		Expected string
+		Actual   string
+		Message  string
+		Diff     string
 	}
 
 	// MessageRequest represents a request interaction
@@ -50,6 +61,8 @@ type (
 		Target    string
 		Value     *http.Request
 		Timestamp time.Time
+		// Error describes why the request failed, e.g. the request did not match any mocks
+		Error string
 	}
 
 	// HttpResponse represents an http response
@@ -102,6 +115,22 @@ func (r *Recorder) AddMessageResponse(m MessageResponse) *Recorder {
 	return r
 }
 
+// AddAssertion add an Assertion to the recorder
+func (r *Recorder) AddAssertion(assertion Assertion) *Recorder {
+	r.Assertions = append(r.Assertions, assertion)
+	return r
+}
+
+// Passed returns false if any of the recorded assertions failed
+func (r *Recorder) Passed() bool {
+	for _, assertion := range r.Assertions {
+		if !assertion.Passed {
+			return false
+		}
+	}
+	return true
+}
+
 // AddTitle add a Title to the recorder
 func (r *Recorder) AddTitle(title string) *Recorder {
 	r.Title = title
@@ -141,5 +170,6 @@ func (r *Recorder) Reset() {
 	r.Title = ""
 	r.SubTitle = ""
 	r.Events = nil
+	r.Assertions = nil
 	r.Meta = nil
 }
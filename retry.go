@@ -0,0 +1,107 @@
+package apitest
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// failFirst fails the first calls of a mock. The copies of a mock share the count of calls
+type failFirst struct {
+	calls      int
+	statusCode int
+	retryAfter time.Duration
+	count      int64
+}
+
+// next returns true if the current call should fail
+func (f *failFirst) next() bool {
+	return atomic.AddInt64(&f.count, 1) <= int64(f.calls)
+}
+
+func (f *failFirst) reset() {
+	if f != nil {
+		atomic.StoreInt64(&f.count, 0)
+	}
+}
+
+func (f *failFirst) response() *http.Response {
+	res := &http.Response{
+		StatusCode:    f.statusCode,
+		Status:        fmt.Sprintf("%d %s", f.statusCode, http.StatusText(f.statusCode)),
+		Header:        http.Header{},
+		Body:          http.NoBody,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		ContentLength: 0,
+	}
+	if f.retryAfter > 0 {
+		res.Header.Set("Retry-After", strconv.Itoa(int(math.Ceil(f.retryAfter.Seconds()))))
+	}
+	return res
+}
+
+// FailFirst responds with the status code and a Retry-After header for the first n calls of the mock, then with the
+// defined response, e.g. to test that a client retries failed requests. The failed calls are in addition to the
+// number of calls set by Times. Retry-After is rounded up to whole seconds and is not sent if retryAfter is zero
+func (r *MockResponse) FailFirst(n int, statusCode int, retryAfter time.Duration) *MockResponse {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.failFirst = &failFirst{calls: n, statusCode: statusCode, retryAfter: retryAfter}
+	return r
+}
+
+// RateLimitFirst responds with 429 Too Many Requests and a Retry-After header for the first n calls, see FailFirst
+func (r *MockResponse) RateLimitFirst(n int, retryAfter time.Duration) *MockResponse {
+	return r.FailFirst(n, http.StatusTooManyRequests, retryAfter)
+}
+
+// UnavailableFirst responds with 503 Service Unavailable and a Retry-After header for the first n calls, see FailFirst
+func (r *MockResponse) UnavailableFirst(n int, retryAfter time.Duration) *MockResponse {
+	return r.FailFirst(n, http.StatusServiceUnavailable, retryAfter)
+}
+
+// failedCalls returns the number of calls that fail before the mock responds with the defined response
+func (r *MockResponse) failedCalls() int {
+	if r.failFirst == nil {
+		return 0
+	}
+	return r.failFirst.calls
+}
+
+// CallIntervals returns the time between each call received by the mock and the previous call, e.g. to verify the
+// backoff of a client that retries failed requests
+func (m *Mock) CallIntervals() []time.Duration {
+	calls := m.Calls()
+	var intervals []time.Duration
+	for i := 1; i < len(calls); i++ {
+		intervals = append(intervals, calls[i].Timestamp.Sub(calls[i-1].Timestamp))
+	}
+	return intervals
+}
+
+// AssertRetries fails the test unless the mock received the first call followed by the given number of retries,
+// each received at least minInterval after the previous call
+func (m *Mock) AssertRetries(t TestingT, retries int, minInterval time.Duration) {
+	calls := m.Calls()
+	if len(calls) != retries+1 {
+		received := len(calls) - 1
+		if received < 0 {
+			received = 0
+		}
+		t.Errorf("mock %s expected %d retries but received %d\n\n%s",
+			m.request.describe(), retries, received, formatMockCalls(calls))
+		return
+	}
+	for i, interval := range m.CallIntervals() {
+		if interval < minInterval {
+			t.Errorf("mock %s expected retries at least %s apart but retry %d was received after %s",
+				m.request.describe(), minInterval, i+1, interval)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package apitest
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetry_FailFirst(t *testing.T) {
+	server := NewMockServer(NewMock().Get("/user").RespondWith().UnavailableFirst(2, 1500*time.Millisecond).Body("jon").End())
+
+	for _, expected := range []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable} {
+		res, err := http.Get(server.URL + "/user")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res.StatusCode)
+		assert.Equal(t, "2", res.Header.Get("Retry-After"))
+	}
+	res, err := http.Get(server.URL + "/user")
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "jon", string(body))
+	assert.NoError(t, server.Close())
+}
+
+func TestRetry_FailFirstWithoutRetryAfter(t *testing.T) {
+	res := (&failFirst{calls: 1, statusCode: http.StatusTooManyRequests}).response()
+
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	assert.Equal(t, "429 Too Many Requests", res.Status)
+	assert.Equal(t, "", res.Header.Get("Retry-After"))
+}
+
+func TestRetry_CallIntervals(t *testing.T) {
+	mock := NewMock().Get("/user").RespondWith().End()
+	started := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, offset := range []time.Duration{0, 100 * time.Millisecond, 300 * time.Millisecond} {
+		mock.calls.add(MockCall{Request: &http.Request{Method: http.MethodGet, URL: mock.request.url}, Timestamp: started.Add(offset)})
+	}
+
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, mock.CallIntervals())
+
+	recorder := &retryTestingT{}
+	mock.AssertRetries(recorder, 2, 100*time.Millisecond)
+	assert.Equal(t, 0, len(recorder.errors))
+
+	mock.AssertRetries(recorder, 2, 150*time.Millisecond)
+	assert.Equal(t, []string{"mock GET /user expected retries at least 150ms apart but retry 1 was received after 100ms"}, recorder.errors)
+
+	recorder = &retryTestingT{}
+	mock.AssertRetries(recorder, 3, 0)
+	assert.Equal(t, []string{"mock GET /user expected 3 retries but received 2\n\nmock calls received:\n" +
+		"1. GET /user -> no response\n2. GET /user -> no response\n3. GET /user -> no response"}, recorder.errors)
+}
+
+type retryTestingT struct {
+	errors []string
+}
+
+func (r *retryTestingT) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *retryTestingT) Fatal(args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprint(args...))
+}
+
+func (r *retryTestingT) Fatalf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
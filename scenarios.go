@@ -0,0 +1,87 @@
+package apitest
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ScenarioStarted is the initial state of every scenario
+const ScenarioStarted = "started"
+
+// Scenarios holds the current state of the mock scenarios. Mocks can require a scenario to be in a given state
+// using Mock.InState and move the scenario to a new state using Mock.TransitionTo, which allows stateful
+// interactions such as a payment that is pending until it is completed to be mocked
+type Scenarios struct {
+	mu     sync.Mutex
+	states map[string]string
+}
+
+// NewScenarios creates a new scenario state store where all scenarios are in the ScenarioStarted state
+func NewScenarios() *Scenarios {
+	return &Scenarios{states: map[string]string{}}
+}
+
+// State returns the current state of the named scenario. Use an empty name for the default scenario
+func (s *Scenarios) State(name string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state(name)
+}
+
+// SetState moves the named scenario to the given state
+func (s *Scenarios) SetState(name, state string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[name] = state
+}
+
+// Reset moves all scenarios back to the ScenarioStarted state
+func (s *Scenarios) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states = map[string]string{}
+}
+
+func (s *Scenarios) state(name string) string {
+	if s == nil {
+		return ScenarioStarted
+	}
+	if state, ok := s.states[name]; ok {
+		return state
+	}
+	return ScenarioStarted
+}
+
+func (s *Scenarios) lock() func() {
+	if s == nil {
+		return func() {}
+	}
+	s.mu.Lock()
+	return s.mu.Unlock
+}
+
+// matchesState checks whether the scenario of the mock is in the state required by the mock
+func (s *Scenarios) matchesState(mock *Mock) error {
+	if mock.requiredState == "" {
+		return nil
+	}
+	current := s.state(mock.scenario)
+	return errorOrNil(current == mock.requiredState, func() string {
+		return fmt.Sprintf("scenario %s is in state %s but mock requires state %s", scenarioName(mock.scenario), current, mock.requiredState)
+	})
+}
+
+// transition moves the scenario of the mock to the new state defined by the mock
+func (s *Scenarios) transition(mock *Mock) {
+	if s == nil || mock.newState == "" {
+		return
+	}
+	s.states[mock.scenario] = mock.newState
+}
+
+func scenarioName(name string) string {
+	if name == "" {
+		return "'default'"
+	}
+	return fmt.Sprintf("'%s'", name)
+}
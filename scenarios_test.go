@@ -0,0 +1,92 @@
+package apitest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestScenarios_MatchesMocksInCurrentState(t *testing.T) {
+	scenarios := NewScenarios()
+	pending := NewMock().InState(ScenarioStarted).Get("/payment").RespondWith().Body("pending").AnyTimes().End()
+	pay := NewMock().TransitionTo("paid").Post("/payment").RespondWith().Status(http.StatusAccepted).End()
+	completed := NewMock().InState("paid").Get("/payment").RespondWith().Body("completed").AnyTimes().End()
+	mocks := []*Mock{pending, pay, completed}
+
+	res, err := matches(httptest.NewRequest(http.MethodGet, "/payment", nil), mocks, scenarios)
+	assert.NoError(t, err)
+	assert.Equal(t, "pending", res.body)
+
+	res, err = matches(httptest.NewRequest(http.MethodPost, "/payment", nil), mocks, scenarios)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusAccepted, res.statusCode)
+	assert.Equal(t, "paid", scenarios.State(""))
+
+	res, err = matches(httptest.NewRequest(http.MethodGet, "/payment", nil), mocks, scenarios)
+	assert.NoError(t, err)
+	assert.Equal(t, "completed", res.body)
+
+	scenarios.Reset()
+	assert.Equal(t, ScenarioStarted, scenarios.State(""))
+}
+
+func TestScenarios_NamedScenariosAreIndependent(t *testing.T) {
+	scenarios := NewScenarios()
+	scenarios.SetState("payments", "paid")
+	refund := NewMock().InScenario("refunds").InState("paid").Post("/refund").RespondWith().End()
+
+	_, err := matches(httptest.NewRequest(http.MethodPost, "/refund", nil), []*Mock{refund}, scenarios)
+
+	assert.True(t, strings.Contains(err.Error(), "scenario 'refunds' is in state started but mock requires state paid"))
+	assert.Equal(t, ScenarioStarted, scenarios.State("refunds"))
+	assert.Equal(t, "paid", scenarios.State("payments"))
+}
+
+func TestScenarios_CustomMatcherCanReadState(t *testing.T) {
+	scenarios := NewScenarios()
+	scenarios.SetState("payments", "paid")
+	refund := NewMock().
+		Post("/refund").
+		AddMatcher(func(req *http.Request, _ *MockRequest) error {
+			return errorOrNil(scenarios.State("payments") == "paid", func() string { return "payment not paid" })
+		}).
+		RespondWith().
+		End()
+
+	_, err := matches(httptest.NewRequest(http.MethodPost, "/refund", nil), []*Mock{refund}, scenarios)
+
+	assert.NoError(t, err)
+}
+
+func TestApiTest_Scenarios(t *testing.T) {
+	scenarios := NewScenarios()
+	getOrder := NewMock().InState(ScenarioStarted).Get("http://localhost:8080/order").RespondWith().Body("pending").AnyTimes().End()
+	payOrder := NewMock().TransitionTo("paid").Post("http://localhost:8080/order/pay").RespondWith().End()
+	getPaidOrder := NewMock().InState("paid").Get("http://localhost:8080/order").RespondWith().Body("completed").End()
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		for _, step := range []string{"GET /order", "GET /order", "POST /order/pay", "GET /order"} {
+			parts := strings.Split(step, " ")
+			req, _ := http.NewRequest(parts[0], "http://localhost:8080"+parts[1], nil)
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			body, _ := ioutil.ReadAll(res.Body)
+			_, _ = w.Write(append(body, ' '))
+		}
+	}
+
+	New().
+		Scenarios(scenarios).
+		Mocks(getOrder, payOrder, getPaidOrder).
+		HandlerFunc(handler).
+		Get("/").
+		Expect(t).
+		Body("pending pending  completed ").
+		End()
+
+	assert.Equal(t, "paid", scenarios.State(""))
+}
@@ -34,6 +34,32 @@ const reportTemplate = `<!DOCTYPE html>
             z-index: 99;
         }
 
+        .waterfall-lane {
+            border-bottom: 1px solid #eee;
+            height: 28px;
+            position: relative;
+        }
+
+        .waterfall-bar {
+            border-radius: 2px;
+            color: white;
+            font-size: 12px;
+            height: 22px;
+            line-height: 22px;
+            min-width: 2px;
+            overflow: hidden;
+            padding: 0 4px;
+            position: absolute;
+            top: 3px;
+            white-space: nowrap;
+        }
+
+        .waterfall-bar:hover {
+            color: white;
+            opacity: 0.8;
+            text-decoration: none;
+        }
+
         .copy-to-clipboard-button {
             background-color: #fff;
             border: 1px solid #eee;
@@ -48,13 +74,46 @@ const reportTemplate = `<!DOCTYPE html>
 <!-- THIS CODE IS AUTOGENERATED. DO NOT EDIT -->
 <div class="container-fluid">
     <h2>{{printf "%.100s" .Title }}</h2>
-    <span class="{{ .BadgeClass }}">{{ .StatusCode }}</span>
+    {{if .HasStatus }}<span class="{{ .BadgeClass }}">{{ .StatusCode }}</span>{{end}}
+    {{if .Assertions }}{{if .Passed }}<span class="badge badge-success">PASSED</span>{{else}}<span class="badge badge-danger">FAILED</span>{{end}}{{end}}
     <p class="lead">{{ .SubTitle }}</p>
     <div class="card text-center">
         <div class="card-body">
             <div id="d" class="justify-content-center"></div>
         </div>
     </div>
+    {{if .Waterfall.Lanes }}
+    <br><br>
+    <p class="lead">Timeline <small class="text-muted">{{ .Waterfall.Duration }}</small></p>
+    <div class="card">
+        <div class="card-body">
+            {{ range $lane := .Waterfall.Lanes }}
+            <div class="waterfall-lane">
+                {{ range $bar := $lane }}
+                <a class="waterfall-bar {{ $bar.Class }}" href="#log-{{ $bar.LogIndex }}" style="left: {{ $bar.Left }}%; width: {{ $bar.Width }}%;" title="{{ $bar.Label }} (+{{ $bar.StartOffset }} to +{{ $bar.EndOffset }})">{{ $bar.Label }}</a>
+                {{ end }}
+            </div>
+            {{ end }}
+        </div>
+    </div>
+    {{end}}
+    {{if .Assertions }}
+    <br><br>
+    <p class="lead">Assertions</p>
+    <ul class="list-group">
+        {{ range $i, $a := .Assertions }}
+        <li id="assertion-{{$i}}" class="list-group-item {{if $a.Passed }}list-group-item-success{{else}}list-group-item-danger{{end}}">
+            <span>{{if $a.Passed }}&#10004;{{else}}&#10008;{{end}}</span> <strong>{{ $a.Name }}</strong>
+            {{if not $a.Passed }}
+                {{if $a.Message }}<div>{{ $a.Message }}</div>{{end}}
+                {{if $a.Expected }}<div><small>expected:</small> <code>{{ $a.Expected }}</code></div>{{end}}
+                {{if $a.Actual }}<div><small>actual:</small> <code>{{ $a.Actual }}</code></div>{{end}}
+                {{if $a.Diff }}<pre style="margin-bottom: 0; border: 1px solid #eee; background-color: #fff;">{{ $a.Diff }}</pre>{{end}}
+            {{end}}
+        </li>
+        {{ end }}
+    </ul>
+    {{end}}
     <br><br>
     <p class="lead">Event Log</p>
     <table class="table">
@@ -70,6 +129,7 @@ const reportTemplate = `<!DOCTYPE html>
             <th scope="row">{{ inc $i }}</th>
             <td>
                 <pre>{{ $e.Header }}</pre>
+                {{if $e.Error }}<pre class="alert alert-danger">{{ $e.Error }}</pre>{{end}}
                 {{if $e.Body }}
                     <pre style="max-height: 1000px; margin-bottom: 0; border: 1px solid #eee;"><code id="event-message-{{$i}}">{{ $e.Body }}</code></pre>
                     <button class="copy-to-clipboard-button" data-clipboard-target="#event-message-{{$i}}">copy to clipboard</button>
@@ -83,6 +143,22 @@ const reportTemplate = `<!DOCTYPE html>
 <button onclick="topFunction()" id="scroll-to-top-button" title="Go to top">Back to top</button>
 <script>
     Diagram.parse("{{ .WebSequenceDSL }}").drawSVG("d", {theme: 'simple', 'font-size': 14});
+
+    // draw the failed requests, e.g. requests that did not match any mocks, in red
+    var failedRows = {{ .FailedRows }} || [];
+    var diagramText = document.getElementById('d').getElementsByTagName('text');
+    for (var i = 0; i < diagramText.length; i++) {
+        var row = diagramText[i].textContent.match(/^\((\d+)\)/);
+        if (row && failedRows.indexOf(parseInt(row[1], 10)) >= 0) {
+            diagramText[i].setAttribute('fill', '#dc3545');
+            for (var line = diagramText[i].nextElementSibling; line; line = line.nextElementSibling) {
+                if (line.tagName === 'path') {
+                    line.setAttribute('stroke', '#dc3545');
+                    break;
+                }
+            }
+        }
+    }
 </script>
 <style>
     
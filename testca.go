@@ -0,0 +1,153 @@
+package apitest
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CertificateAuthority is an ephemeral certificate authority that issues a certificate for each host on demand, so
+// that mocks can be served over TLS under real hostnames to clients that verify certificates
+type CertificateAuthority struct {
+	cert   *x509.Certificate
+	key    *ecdsa.PrivateKey
+	pem    []byte
+	mu     sync.Mutex
+	leaves map[string]*tls.Certificate
+}
+
+// TestCA creates an ephemeral certificate authority that is valid for 24 hours
+func TestCA() *CertificateAuthority {
+	ca, err := newCertificateAuthority()
+	if err != nil {
+		panic(err)
+	}
+	return ca
+}
+
+func newCertificateAuthority() (*CertificateAuthority, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{Organization: []string{"apitest"}, CommonName: "apitest test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return nil, err
+	}
+	return &CertificateAuthority{
+		cert:   cert,
+		key:    key,
+		pem:    pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		leaves: map[string]*tls.Certificate{},
+	}, nil
+}
+
+func newSerialNumber() (*big.Int, error) {
+	return rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+}
+
+// Certificate returns the certificate of the host, e.g. api.example.com or 127.0.0.1, issuing it if it does not exist
+func (ca *CertificateAuthority) Certificate(host string) (*tls.Certificate, error) {
+	ca.mu.Lock()
+	defer ca.mu.Unlock()
+	if leaf, ok := ca.leaves[host]; ok {
+		return leaf, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serialNumber, err := newSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{Organization: []string{"apitest"}, CommonName: host},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		template.IPAddresses = []net.IP{ip}
+	} else {
+		template.DNSNames = []string{host}
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		return nil, err
+	}
+	leaf := &tls.Certificate{Certificate: [][]byte{der, ca.cert.Raw}, PrivateKey: key}
+	ca.leaves[host] = leaf
+	return leaf, nil
+}
+
+// CertificatePEM returns the PEM encoded certificate of the authority, e.g. to be trusted by a subprocess
+func (ca *CertificateAuthority) CertificatePEM() []byte {
+	return ca.pem
+}
+
+// CertPool returns a pool containing the certificate of the authority
+func (ca *CertificateAuthority) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return pool
+}
+
+// Client returns a http client that trusts the certificates issued by the authority
+func (ca *CertificateAuthority) Client() *http.Client {
+	return &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: ca.CertPool()}}}
+}
+
+// ServerConfig returns a TLS configuration that serves the certificate of the server name requested by the client,
+// or of the local address of the connection if the client did not send a server name
+func (ca *CertificateAuthority) ServerConfig() *tls.Config {
+	return ca.serverConfig("")
+}
+
+// serverConfig returns a TLS configuration that serves the certificate of the server name requested by the client,
+// or of the given host if the client did not send a server name
+func (ca *CertificateAuthority) serverConfig(host string) *tls.Config {
+	return &tls.Config{
+		NextProtos: []string{"http/1.1"},
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			if hello.ServerName != "" {
+				return ca.Certificate(hello.ServerName)
+			}
+			if host == "" && hello.Conn != nil {
+				if local, _, err := net.SplitHostPort(hello.Conn.LocalAddr().String()); err == nil {
+					return ca.Certificate(local)
+				}
+			}
+			return ca.Certificate(host)
+		},
+	}
+}
@@ -0,0 +1,73 @@
+package apitest
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestTestCA_IssuesCertificates(t *testing.T) {
+	ca := TestCA()
+
+	for _, host := range []string{"api.example.com", "127.0.0.1"} {
+		leaf, err := ca.Certificate(host)
+		assert.NoError(t, err)
+		cert, err := x509.ParseCertificate(leaf.Certificate[0])
+		assert.NoError(t, err)
+		_, err = cert.Verify(x509.VerifyOptions{DNSName: host, Roots: ca.CertPool()})
+		assert.NoError(t, err)
+
+		cached, _ := ca.Certificate(host)
+		assert.True(t, leaf == cached)
+	}
+
+	pool := x509.NewCertPool()
+	assert.True(t, pool.AppendCertsFromPEM(ca.CertificatePEM()))
+}
+
+func TestTestCA_ServerConfig(t *testing.T) {
+	ca := TestCA()
+	serverConn, clientConn := net.Pipe()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	go func() {
+		_ = tls.Server(serverConn, ca.ServerConfig()).Handshake()
+	}()
+
+	client := tls.Client(clientConn, &tls.Config{ServerName: "api.example.com", RootCAs: ca.CertPool()})
+
+	assert.NoError(t, client.Handshake())
+	assert.Equal(t, "api.example.com", client.ConnectionState().PeerCertificates[0].Subject.CommonName)
+}
+
+func TestTestCA_MockServerServesHosts(t *testing.T) {
+	ca := TestCA()
+	server := NewTLSMockServerWithCA(ca,
+		NewMock().Get("https://api.example.com/user").RespondWith().Body("jon").End(),
+		NewMock().Get("https://auth.example.com/token").RespondWith().Body("abc").End(),
+	)
+	defer server.Close()
+
+	res, err := server.Client().Get("https://api.example.com/user")
+	assert.NoError(t, err)
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, "jon", string(body))
+	assert.Equal(t, "api.example.com", res.TLS.PeerCertificates[0].Subject.CommonName)
+
+	res, err = server.Client().Get("https://auth.example.com/token")
+	assert.NoError(t, err)
+	body, _ = ioutil.ReadAll(res.Body)
+	assert.Equal(t, "abc", string(body))
+
+	res, err = ca.Client().Get(server.URL + "/user")
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+
+	untrusted := &http.Client{Transport: &http.Transport{DialContext: server.DialContext}}
+	_, err = untrusted.Get("https://api.example.com/user")
+	assert.True(t, err != nil)
+	assert.True(t, server.CA() == ca)
+}
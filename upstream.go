@@ -0,0 +1,80 @@
+package apitest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+)
+
+// upstreams are in-process handlers that serve the outbound requests sent to their host
+type upstreams struct {
+	hosts    []string
+	handlers map[string]http.Handler
+}
+
+func (u *upstreams) add(host string, handler http.Handler) {
+	if handler == nil {
+		panic("upstream handler must not be nil for host " + host)
+	}
+	if u.handlers == nil {
+		u.handlers = map[string]http.Handler{}
+	}
+	if _, ok := u.handlers[host]; !ok {
+		u.hosts = append(u.hosts, host)
+	}
+	u.handlers[host] = handler
+}
+
+// handler returns the upstream serving the request host and the name of the upstream, or nil if there is none
+func (u *upstreams) handler(req *http.Request) (http.Handler, string) {
+	if u == nil {
+		return nil, ""
+	}
+	for _, host := range u.hosts {
+		if host == req.URL.Host || host == req.URL.Hostname() {
+			return u.handlers[host], host
+		}
+	}
+	return nil, ""
+}
+
+type upstreamKey struct{}
+
+// serveUpstream serves the outbound request using the upstream handler. Requests made by the handler using the
+// context of the inbound request are attributed to the upstream in the report. A panic in the handler is served as a
+// 500 Internal Server Error containing the panic value
+func serveUpstream(handler http.Handler, host string, req *http.Request) (res *http.Response) {
+	inbound := req.Clone(context.WithValue(req.Context(), upstreamKey{}, host))
+	inbound.RequestURI = req.URL.RequestURI()
+	if inbound.Host == "" {
+		inbound.Host = req.URL.Host
+	}
+	if inbound.Body == nil {
+		inbound.Body = http.NoBody
+	}
+
+	defer func() {
+		if err := recover(); err != nil {
+			recorder := httptest.NewRecorder()
+			http.Error(recorder, fmt.Sprintf("upstream %s panicked: %v", host, err), http.StatusInternalServerError)
+			res = recorder.Result()
+			res.Request = req
+		}
+	}()
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, inbound)
+	res = recorder.Result()
+	res.Request = req
+	return res
+}
+
+// requestSource returns the name of the upstream that sent the outbound request, or the system under test if the
+// request was not sent by an upstream
+func requestSource(req *http.Request) string {
+	if host, ok := req.Context().Value(upstreamKey{}).(string); ok {
+		return host
+	}
+	return SystemUnderTestDefaultName
+}
@@ -0,0 +1,57 @@
+package apitest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUpstream_MatchesHost(t *testing.T) {
+	users := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	orders := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	u := &upstreams{}
+	u.add("users", users)
+	u.add("orders:8080", orders)
+
+	_, host := u.handler(httptest.NewRequest(http.MethodGet, "http://users:8080/users", nil))
+	assert.Equal(t, "users", host)
+	_, host = u.handler(httptest.NewRequest(http.MethodGet, "http://orders:8080/orders", nil))
+	assert.Equal(t, "orders:8080", host)
+	handler, _ := u.handler(httptest.NewRequest(http.MethodGet, "http://orders:8081/orders", nil))
+	assert.True(t, handler == nil)
+}
+
+func TestUpstream_ServesRequest(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("X-Source", requestSource(r))
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(r.Host + " " + r.RequestURI + " " + string(body)))
+	})
+	req, _ := http.NewRequest(http.MethodPost, "http://users:8080/users?a=1", strings.NewReader("jon"))
+
+	res := serveUpstream(handler, "users", req)
+
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, http.StatusCreated, res.StatusCode)
+	assert.Equal(t, "users:8080 /users?a=1 jon", string(body))
+	assert.Equal(t, "users", res.Header.Get("X-Source"))
+	assert.Equal(t, req, res.Request)
+	assert.Equal(t, SystemUnderTestDefaultName, requestSource(req))
+}
+
+func TestUpstream_RecoversFromPanic(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("nil user")
+	})
+	req, _ := http.NewRequest(http.MethodGet, "http://users:8080/users", nil)
+
+	res := serveUpstream(handler, "users", req)
+
+	body, _ := ioutil.ReadAll(res.Body)
+	assert.Equal(t, http.StatusInternalServerError, res.StatusCode)
+	assert.Equal(t, "upstream users panicked: nil user\n", string(body))
+	assert.Equal(t, req, res.Request)
+}
@@ -184,6 +184,7 @@ type recordingConnWithQuery struct {
 // It also sends the query as a message to the recorder
 func (conn *recordingConnWithQuery) Query(query string, args []driver.Value) (driver.Rows, error) {
 	if connQuery, ok := conn.Conn.(driver.Queryer); ok {
+		started := time.Now().UTC()
 		rows, err := connQuery.Query(query, args)
 		if err != nil {
 			return nil, err
@@ -199,7 +200,7 @@ func (conn *recordingConnWithQuery) Query(query string, args []driver.Value) (dr
 				Target:    conn.sourceName,
 				Header:    "SQL Query",
 				Body:      recorderBody,
-				Timestamp: time.Now().UTC(),
+				Timestamp: started,
 			})
 		}
 
@@ -217,6 +218,7 @@ type recordingConnWithQueryContext struct {
 // It also sends the query as a message to the recorder
 func (conn *recordingConnWithQueryContext) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
 	if connQueryCtx, ok := conn.Conn.(driver.QueryerContext); ok {
+		started := time.Now().UTC()
 		rows, err := connQueryCtx.QueryContext(ctx, query, args)
 		if err != nil {
 			return nil, err
@@ -236,7 +238,7 @@ func (conn *recordingConnWithQueryContext) QueryContext(ctx context.Context, que
 				Target:    conn.sourceName,
 				Header:    "SQL Query",
 				Body:      recorderBody,
-				Timestamp: time.Now().UTC(),
+				Timestamp: started,
 			})
 		}
 
@@ -254,6 +256,7 @@ type recordingConnWithExec struct {
 // It also sends the query and the number of rows affected as messages to the recorder
 func (conn *recordingConnWithExec) Exec(query string, args []driver.Value) (driver.Result, error) {
 	if connExec, ok := conn.Conn.(driver.Execer); ok {
+		started := time.Now().UTC()
 		result, err := connExec.Exec(query, args)
 		if err != nil {
 			return nil, err
@@ -269,7 +272,7 @@ func (conn *recordingConnWithExec) Exec(query string, args []driver.Value) (driv
 				Target:    conn.sourceName,
 				Header:    "SQL Query",
 				Body:      recorderBody,
-				Timestamp: time.Now().UTC(),
+				Timestamp: started,
 			})
 		}
 
@@ -298,6 +301,7 @@ type recordingConnWithExecContext struct {
 // It also sends the query and the number of rows affected as messages to the recorder
 func (conn *recordingConnWithExecContext) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
 	if connExecCtx, ok := conn.Conn.(driver.ExecerContext); ok {
+		started := time.Now().UTC()
 		result, err := connExecCtx.ExecContext(ctx, query, args)
 		if err != nil {
 			return nil, err
@@ -317,7 +321,7 @@ func (conn *recordingConnWithExecContext) ExecContext(ctx context.Context, query
 				Target:    conn.sourceName,
 				Header:    "SQL Query",
 				Body:      recorderBody,
-				Timestamp: time.Now().UTC(),
+				Timestamp: started,
 			})
 		}
 
@@ -438,6 +442,7 @@ func (stmt *recordingStmt) NumInput() int {
 // Exec wraps the underlying stmt's Exec method
 // It also sends the query and the number of rows affected as messages to the recorder
 func (stmt *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
+	started := time.Now().UTC()
 	result, err := stmt.Stmt.Exec(args)
 	if stmt.recorder != nil {
 		recorderBody := stmt.query
@@ -449,7 +454,7 @@ func (stmt *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
 			Target:    stmt.sourceName,
 			Header:    "SQL Query",
 			Body:      recorderBody,
-			Timestamp: time.Now().UTC(),
+			Timestamp: started,
 		})
 	}
 
@@ -470,6 +475,7 @@ func (stmt *recordingStmt) Exec(args []driver.Value) (driver.Result, error) {
 // Query wraps the underlying stmt's Query method
 // It also sends the query as a message to the recorder
 func (stmt *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
+	started := time.Now().UTC()
 	rows, err := stmt.Stmt.Query(args)
 
 	if stmt.recorder != nil {
@@ -482,7 +488,7 @@ func (stmt *recordingStmt) Query(args []driver.Value) (driver.Rows, error) {
 			Target:    stmt.sourceName,
 			Header:    "SQL Query",
 			Body:      recorderBody,
-			Timestamp: time.Now().UTC(),
+			Timestamp: started,
 		})
 	}
 
@@ -497,6 +503,7 @@ type recordingStmtWithExecContext struct {
 // It also sends the query and the number of rows affected as messages to the recorder
 func (stmt *recordingStmtWithExecContext) ExecContext(ctx context.Context, args []driver.NamedValue) (driver.Result, error) {
 	if stmtExecCtx, ok := stmt.Stmt.(driver.StmtExecContext); ok {
+		started := time.Now().UTC()
 		result, err := stmtExecCtx.ExecContext(ctx, args)
 		if err != nil {
 			return nil, err
@@ -517,7 +524,7 @@ func (stmt *recordingStmtWithExecContext) ExecContext(ctx context.Context, args
 				Target:    stmt.sourceName,
 				Header:    "SQL Query",
 				Body:      recorderBody,
-				Timestamp: time.Now().UTC(),
+				Timestamp: started,
 			})
 		}
 
@@ -546,6 +553,7 @@ type recordingStmtWithQueryContext struct {
 // It also sends the query as a message to the recorder
 func (stmt *recordingStmtWithQueryContext) QueryContext(ctx context.Context, args []driver.NamedValue) (driver.Rows, error) {
 	if stmtQueryCtx, ok := stmt.Stmt.(driver.StmtQueryContext); ok {
+		started := time.Now().UTC()
 		rows, err := stmtQueryCtx.QueryContext(ctx, args)
 		if err != nil {
 			return nil, err
@@ -566,7 +574,7 @@ func (stmt *recordingStmtWithQueryContext) QueryContext(ctx context.Context, arg
 				Target:    stmt.sourceName,
 				Header:    "SQL Query",
 				Body:      recorderBody,
-				Timestamp: time.Now().UTC(),
+				Timestamp: started,
 			})
 		}
 